@@ -0,0 +1,149 @@
+package tplutil
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// SetBuilder assembles single associated template set from several
+// sources: files on disk, io/fs filesystems and inline strings. Every
+// source is stripped with Strip before parsing, unless options passed to
+// AddGlob say otherwise.
+//
+// Sources are parsed in order they were added, so later additions
+// override templates with the same name defined earlier. First added
+// template becomes the root of resulting set.
+//
+//	tpl, err := tplutil.NewSetBuilder().
+//		Funcs(tplutil.Last).
+//		AddFS(embedded, "templates/*.tpl").
+//		AddGlob("overrides/*.tpl").
+//		AddString("footer", `{{"\n"}}`).
+//		Build()
+type SetBuilder struct {
+	funcs   template.FuncMap
+	sources []builderSource
+}
+
+// builderSource reads templates of single added source, which are parsed
+// with config.
+type builderSource struct {
+	read   func() ([]source, error)
+	config *parseConfig
+}
+
+// NewSetBuilder returns empty SetBuilder.
+func NewSetBuilder() *SetBuilder {
+	return &SetBuilder{funcs: template.FuncMap{}}
+}
+
+// Funcs adds functions to the resulting template set. Functions are
+// available in every template of the set regardless of the order they
+// were added in.
+func (b *SetBuilder) Funcs(funcs template.FuncMap) *SetBuilder {
+	for name, fn := range funcs {
+		b.funcs[name] = fn
+	}
+
+	return b
+}
+
+// AddGlob adds files matching pattern, like ParseGlob does, including
+// its errors for missing files. Templates are named after base names of
+// the files. Options apply to files of this pattern only.
+func (b *SetBuilder) AddGlob(pattern string, opts ...ParseOption) *SetBuilder {
+	config := newParseConfig(opts)
+
+	b.add(func() ([]source, error) {
+		sources, err := readGlob(pattern, config.excludes)
+		if err != nil {
+			return nil, err
+		}
+		for i := range sources {
+			sources[i].name = filepath.Base(sources[i].filename)
+		}
+		return sources, nil
+	}, config)
+
+	return b
+}
+
+// AddFS adds files from fsys matching any of patterns, like
+// template.ParseFS does. Templates are named after base names of the
+// files.
+func (b *SetBuilder) AddFS(fsys fs.FS, patterns ...string) *SetBuilder {
+	b.add(func() ([]source, error) {
+		sources := []source{}
+		for _, pattern := range patterns {
+			filenames, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return nil, err
+			}
+			if len(filenames) == 0 {
+				return nil, fmt.Errorf("template: pattern matches no files: %#q", pattern)
+			}
+			for _, filename := range filenames {
+				content, err := fs.ReadFile(fsys, filename)
+				if err != nil {
+					return nil, err
+				}
+				sources = append(sources, source{path.Base(filename), filename, string(content)})
+			}
+		}
+		return sources, nil
+	}, &parseConfig{})
+
+	return b
+}
+
+// AddString adds template named name with given text.
+func (b *SetBuilder) AddString(name, text string) *SetBuilder {
+	b.add(func() ([]source, error) {
+		return []source{{name: name, text: text}}, nil
+	}, &parseConfig{})
+
+	return b
+}
+
+func (b *SetBuilder) add(read func() ([]source, error), config *parseConfig) {
+	// delimiters are reset for every source in Build, so they are known
+	config.ownTemplate = true
+
+	b.sources = append(b.sources, builderSource{read: read, config: config})
+}
+
+// Build reads and parses all added sources and returns resulting template
+// set. First error encountered is returned.
+func (b *SetBuilder) Build() (*template.Template, error) {
+	var tpl *template.Template
+	for _, added := range b.sources {
+		sources, err := added.read()
+		if err != nil {
+			return nil, err
+		}
+		config := added.config
+		for _, src := range sources {
+			name := strings.TrimPrefix(src.name, config.trimPrefix)
+			if tpl == nil {
+				tpl = newTemplate(name).Funcs(b.funcs)
+			}
+			tpl.Delims(config.leftDelim, config.rightDelim)
+			config.apply(tpl)
+			err = parseStripped(tpl, name, src.text, config)
+			if err != nil {
+				if src.filename != "" {
+					err = parseError(src.filename, err)
+				}
+				return nil, err
+			}
+		}
+	}
+	if tpl == nil {
+		return nil, fmt.Errorf("template: no templates added to set")
+	}
+	return tpl, nil
+}
@@ -0,0 +1,132 @@
+package tplutil
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+)
+
+func TestSetBuilder_LaterSourcesOverrideEarlier(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"footer.tpl": "\tdisk footer\n",
+		"header.tpl": "\tdisk header\n",
+	})
+
+	fsys := fstest.MapFS{
+		"templates/main.tpl": {Data: []byte(`
+			{{template "header.tpl"}}|{{template "footer.tpl"}}
+		`)},
+		"templates/header.tpl": {Data: []byte("\tembedded header\n")},
+		"templates/footer.tpl": {Data: []byte("\tembedded footer\n")},
+	}
+
+	tpl, err := NewSetBuilder().
+		AddFS(fsys, "templates/main.tpl", "templates/header.tpl").
+		AddFS(fsys, "templates/footer.tpl").
+		AddGlob(filepath.Join(dir, "*.tpl")).
+		AddString("footer.tpl", "\n\tinline footer\n").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Name() != "main.tpl" {
+		t.Errorf("first added template is not root: %q", tpl.Name())
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "disk header|inline footer" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestSetBuilder_SharesFuncsAcrossSet(t *testing.T) {
+	tpl, err := NewSetBuilder().
+		AddString("main", `{{upper "a"}}{{template "partial"}}`).
+		Funcs(template.FuncMap{"upper": strings.ToUpper}).
+		AddString("partial", `{{upper "b"}}{{lower "C"}}`).
+		Funcs(template.FuncMap{"lower": strings.ToLower}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "ABc" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestSetBuilder_ReturnsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"broken.tpl": "{{.Broken",
+	})
+
+	_, err := NewSetBuilder().AddGlob(filepath.Join(dir, "*.tpl")).Build()
+	if err == nil || !strings.Contains(err.Error(), "broken.tpl") {
+		t.Errorf("expected error mentioning file, got %v", err)
+	}
+
+	_, err = NewSetBuilder().AddGlob(filepath.Join(dir, "*.html")).Build()
+	if err == nil {
+		t.Error("expected error on pattern without matches")
+	}
+
+	_, err = NewSetBuilder().Build()
+	if err == nil {
+		t.Error("expected error on empty set")
+	}
+}
+
+func TestSetBuilder_AddGlobLiteralPath(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewSetBuilder().AddGlob(filepath.Join(dir, "missing.tpl")).Build()
+	if err == nil || !strings.Contains(err.Error(), "file not found") {
+		t.Errorf("expected file not found error, got %v", err)
+	}
+}
+
+func TestSetBuilder_AddGlobAppliesOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl":     "\t<% . %>\n\n\t<% template \"b\" . %>\n",
+		"_skip.tpl": "{{",
+	})
+
+	tpl, err := NewSetBuilder().
+		AddGlob(
+			filepath.Join(dir, "*.tpl"),
+			Exclude("_*"), WithDelims("<%", "%>"), WithStripMode(StripConservative),
+		).
+		AddString("b", "\t[{{.}}]\n").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Lookup("_skip.tpl") != nil {
+		t.Error("excluded file is registered")
+	}
+
+	result, err := ExecuteTemplateToString(tpl, "a.tpl", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "x\n[x]" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
@@ -0,0 +1,129 @@
+package tplutil
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// builtinFuncs lists functions predefined by text/template.
+var builtinFuncs = map[string]bool{
+	"and": true, "or": true, "not": true,
+	"call": true, "index": true, "slice": true, "len": true,
+	"print": true, "printf": true, "println": true,
+	"html": true, "js": true, "urlquery": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+// CheckTemplate statically validates template text without executing it
+// and returns all found problems at once: calls of functions which are
+// neither in funcs nor built-in and references to templates which are not
+// defined in text. Syntax errors, including unbalanced delimiters, are
+// reported as single parse error and stop the check, since parse tree is
+// not available then.
+//
+// Text is stripped before checking, same as parse functions of the package
+// do. Empty result means that no problems were found.
+func CheckTemplate(text string, funcs template.FuncMap) []error {
+	stripped := Strip(text)
+
+	tree := parse.New("text")
+	tree.Mode = parse.SkipFuncCheck
+
+	trees := map[string]*parse.Tree{}
+	_, err := tree.Parse(stripped, "", "", trees)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	names := make([]string, 0, len(trees))
+	for name := range trees {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		checker := &treeChecker{tree: trees[name], trees: trees, funcs: funcs}
+		checker.walk(trees[name].Root)
+		errs = append(errs, checker.errs...)
+	}
+
+	return errs
+}
+
+type treeChecker struct {
+	tree  *parse.Tree
+	trees map[string]*parse.Tree
+	funcs template.FuncMap
+	errs  []error
+}
+
+func (checker *treeChecker) report(node parse.Node, format string, args ...interface{}) {
+	location, _ := checker.tree.ErrorContext(node)
+	checker.errs = append(
+		checker.errs,
+		fmt.Errorf("template: %s: %s", location, fmt.Sprintf(format, args...)),
+	)
+}
+
+func (checker *treeChecker) walk(node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			checker.walk(child)
+		}
+
+	case *parse.ActionNode:
+		checker.walk(node.Pipe)
+
+	case *parse.IfNode:
+		checker.walkBranch(&node.BranchNode)
+
+	case *parse.RangeNode:
+		checker.walkBranch(&node.BranchNode)
+
+	case *parse.WithNode:
+		checker.walkBranch(&node.BranchNode)
+
+	case *parse.TemplateNode:
+		if _, ok := checker.trees[node.Name]; !ok {
+			checker.report(node, "template %q not defined", node.Name)
+		}
+		checker.walk(node.Pipe)
+
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+		for _, command := range node.Cmds {
+			checker.walk(command)
+		}
+
+	case *parse.CommandNode:
+		for _, arg := range node.Args {
+			checker.walk(arg)
+		}
+
+	case *parse.ChainNode:
+		checker.walk(node.Node)
+
+	case *parse.IdentifierNode:
+		_, ok := checker.funcs[node.Ident]
+		if !ok && !builtinFuncs[node.Ident] {
+			checker.report(node, "function %q not defined", node.Ident)
+		}
+	}
+}
+
+func (checker *treeChecker) walkBranch(node *parse.BranchNode) {
+	checker.walk(node.Pipe)
+	checker.walk(node.List)
+	checker.walk(node.ElseList)
+}
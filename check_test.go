@@ -0,0 +1,53 @@
+package tplutil
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCheckTemplate_ReportsEveryProblem(t *testing.T) {
+	errs := CheckTemplate(`
+		{{upper .Name}}
+		{{template "footer" .}}
+	`, template.FuncMap{})
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+
+	if !strings.Contains(errs[0].Error(), `function "upper" not defined`) {
+		t.Errorf("unexpected first error: %s", errs[0])
+	}
+
+	if !strings.Contains(errs[1].Error(), `template "footer" not defined`) {
+		t.Errorf("unexpected second error: %s", errs[1])
+	}
+}
+
+func TestCheckTemplate_AcceptsValidTemplate(t *testing.T) {
+	testcases := []string{
+		`{{define "footer"}}bye{{end}}{{upper .}}{{template "footer"}}`,
+		`{{"}}"}}`,
+		`{"a": {"b": {{.B}}}}`,
+		`{{if eq .A 1}}{{len .B | printf "%d"}}{{end}}`,
+	}
+
+	for _, text := range testcases {
+		errs := CheckTemplate(text, template.FuncMap{"upper": strings.ToUpper})
+		if len(errs) != 0 {
+			t.Errorf("%q: unexpected errors: %v", text, errs)
+		}
+	}
+}
+
+func TestCheckTemplate_ReportsSyntaxErrorOnce(t *testing.T) {
+	errs := CheckTemplate(`
+		{{.Name
+		}}{{.Other
+	`, nil)
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+}
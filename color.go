@@ -0,0 +1,98 @@
+package tplutil
+
+import (
+	"os"
+	"regexp"
+	"sync"
+	"text/template"
+)
+
+// ColorMode defines whether Color functions emit ANSI escape codes.
+type ColorMode int
+
+const (
+	// ColorAuto enables colors unless NO_COLOR environment variable is set
+	// to non-empty value, see https://no-color.org.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways enables colors regardless of environment.
+	ColorAlways
+
+	// ColorNever disables colors, so Color functions return their
+	// arguments unchanged.
+	ColorNever
+)
+
+var colorMode = struct {
+	sync.RWMutex
+	mode ColorMode
+}{mode: ColorAuto}
+
+// reANSI matches CSI sequences, like colors and cursor movements, and OSC
+// sequences, like hyperlinks.
+var reANSI = regexp.MustCompile(
+	`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`,
+)
+
+// Color provides functions to decorate text with ANSI escape codes for
+// terminal output:
+//
+//	{{red "FAIL"}} {{bold .Name}}
+//	{{stripANSI .Output}}   // plain text of colored output
+//
+// Output is not checked to be a terminal, so colors should be turned off
+// with SetColorMode for non-interactive output.
+var Color = template.FuncMap{
+	"red":       colorizer("31"),
+	"green":     colorizer("32"),
+	"yellow":    colorizer("33"),
+	"blue":      colorizer("34"),
+	"magenta":   colorizer("35"),
+	"cyan":      colorizer("36"),
+	"gray":      colorizer("90"),
+	"bold":      colorizer("1"),
+	"dim":       colorizer("2"),
+	"underline": colorizer("4"),
+	"stripANSI": stripANSI,
+}
+
+// SetColorMode sets whether Color functions emit escape codes. Default
+// mode is ColorAuto.
+func SetColorMode(mode ColorMode) {
+	colorMode.Lock()
+	defer colorMode.Unlock()
+
+	colorMode.mode = mode
+}
+
+func colorEnabled() bool {
+	colorMode.RLock()
+	defer colorMode.RUnlock()
+
+	switch colorMode.mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		return os.Getenv("NO_COLOR") == ""
+	}
+}
+
+// colorizer returns function which wraps string into escape sequence
+// with given SGR code, resetting attributes after it.
+func colorizer(code string) func(s string) string {
+	return func(s string) string {
+		if !colorEnabled() {
+			return s
+		}
+
+		return "\x1b[" + code + "m" + s + "\x1b[0m"
+	}
+}
+
+// stripANSI removes ANSI escape sequences from s, regardless of color
+// mode.
+func stripANSI(s string) string {
+	return reANSI.ReplaceAllString(s, "")
+}
@@ -0,0 +1,70 @@
+package tplutil
+
+import (
+	"testing"
+)
+
+func TestColor_Modes(t *testing.T) {
+	defer SetColorMode(ColorAuto)
+
+	red := colorizer("31")
+
+	testcases := []struct {
+		mode     ColorMode
+		noColor  string
+		expected string
+	}{
+		{ColorAlways, "", "\x1b[31mfail\x1b[0m"},
+		{ColorAlways, "1", "\x1b[31mfail\x1b[0m"},
+		{ColorNever, "", "fail"},
+		{ColorAuto, "", "\x1b[31mfail\x1b[0m"},
+		{ColorAuto, "1", "fail"},
+	}
+
+	for _, testcase := range testcases {
+		t.Setenv("NO_COLOR", testcase.noColor)
+		SetColorMode(testcase.mode)
+
+		result := red("fail")
+		if result != testcase.expected {
+			t.Errorf(
+				"mode %d, NO_COLOR=%q: expected %q, got %q",
+				testcase.mode, testcase.noColor, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	testcases := map[string]string{
+		"\x1b[31mred\x1b[0m and \x1b[1;32mbold green\x1b[0m": "red and bold green",
+		"\x1b[2K\x1b[1Gprogress":                             "progress",
+		"\x1b]8;;https://example.com\x07link\x1b]8;;\x07":    "link",
+		"plain text": "plain text",
+		"":           "",
+	}
+
+	for text, expected := range testcases {
+		result := stripANSI(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestStripANSI_RoundTripsColor(t *testing.T) {
+	defer SetColorMode(ColorAuto)
+	SetColorMode(ColorAlways)
+
+	for name, fn := range Color {
+		colorize, ok := fn.(func(string) string)
+		if !ok || name == "stripANSI" {
+			continue
+		}
+
+		result := stripANSI(colorize("text"))
+		if result != "text" {
+			t.Errorf("%s: expected %q, got %q", name, "text", result)
+		}
+	}
+}
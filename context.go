@@ -0,0 +1,29 @@
+package tplutil
+
+import (
+	"context"
+	"text/template"
+)
+
+// Context provides access to request-scoped values of context.Context
+// passed in template data:
+//
+//	{{ctxValue .Ctx "user"}}
+//
+// Only values stored under plain string keys can be accessed, while
+// idiomatic code uses unexported key types, which templates can't
+// construct. So it is meant for simple cases only, and is not included
+// in Default.
+var Context = template.FuncMap{
+	"ctxValue": ctxValue,
+}
+
+// ctxValue returns value stored in ctx under key or nil if there is no
+// such value.
+func ctxValue(ctx context.Context, key string) interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	return ctx.Value(key)
+}
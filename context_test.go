@@ -0,0 +1,44 @@
+package tplutil
+
+import (
+	"context"
+	"testing"
+	"text/template"
+)
+
+func TestCtxValue(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "user", "john")
+
+	if value := ctxValue(ctx, "user"); value != "john" {
+		t.Errorf("present key: expected %q, got %v", "john", value)
+	}
+
+	if value := ctxValue(ctx, "missing"); value != nil {
+		t.Errorf("absent key: expected nil, got %v", value)
+	}
+
+	if value := ctxValue(nil, "user"); value != nil {
+		t.Errorf("nil context: expected nil, got %v", value)
+	}
+}
+
+func TestCtxValue_InTemplate(t *testing.T) {
+	tpl, err := ParseText(template.New("main").Funcs(Context), `
+		{{ctxValue .Ctx "user"}}:{{with ctxValue .Ctx "missing"}}set{{end}}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "user", "john")
+
+	result, err := ExecuteToString(tpl, map[string]interface{}{"Ctx": ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "john:"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,25 @@
+package tplutil
+
+import (
+	"os"
+	"text/template"
+)
+
+// Env provides access to environment variables:
+//
+//	{{env "HOME"}}
+//	{{if flag "ENABLE_DEBUG"}}debug: true{{end}}
+//
+// Template output depends on environment then, so it is not included in
+// Default.
+var Env = template.FuncMap{
+	"env":  os.Getenv,
+	"flag": flag,
+}
+
+// flag reports whether environment variable name is set to truthy value,
+// like `1`, `true`, `yes` or `on`, using the same rules as toBool does
+// for strings. Unset variable is false.
+func flag(name string) bool {
+	return toBool(os.Getenv(name))
+}
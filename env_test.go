@@ -0,0 +1,68 @@
+package tplutil
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestFlag(t *testing.T) {
+	testcases := map[string]bool{
+		"1":        true,
+		"true":     true,
+		"TRUE":     true,
+		" yes ":    true,
+		"Y":        true,
+		"on":       true,
+		"2":        true,
+		"0":        false,
+		"false":    false,
+		"no":       false,
+		"off":      false,
+		"":         false,
+		"maybe":    false,
+		"nan":      false,
+		"inf":      false,
+		"Infinity": false,
+	}
+
+	for value, expected := range testcases {
+		t.Setenv("TPLUTIL_TEST_FLAG", value)
+
+		if flag("TPLUTIL_TEST_FLAG") != expected {
+			t.Errorf("%q: expected %v", value, expected)
+		}
+
+		if toBool(value) != expected {
+			t.Errorf("%q: toBool disagrees with flag", value)
+		}
+	}
+}
+
+func TestFlag_Unset(t *testing.T) {
+	if flag("TPLUTIL_TEST_FLAG_UNSET") {
+		t.Error("unset variable should be false")
+	}
+}
+
+func TestEnv_InTemplate(t *testing.T) {
+	t.Setenv("TPLUTIL_TEST_FLAG", "yes")
+	t.Setenv("TPLUTIL_TEST_NAME", "prod")
+
+	tpl, err := ParseText(template.New("main").Funcs(Env), `
+		{{env "TPLUTIL_TEST_NAME"}}:
+		{{if flag "TPLUTIL_TEST_FLAG"}}debug{{end}}:
+		{{if flag "TPLUTIL_TEST_FLAG_UNSET"}}verbose{{end}}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "prod:debug:" {
+		t.Errorf("expected %q, got %q", "prod:debug:", result)
+	}
+}
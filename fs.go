@@ -0,0 +1,199 @@
+package tplutil
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// ParseFS do the same as template.ParseFS(), but will allow to use sparse
+// syntax in files, like ParseGlob() does.
+func ParseFS(
+	tpl *template.Template, fsys fs.FS, patterns ...string,
+) (*template.Template, error) {
+	return ParseFSOpts(tpl, fsys, patterns)
+}
+
+// ParseFSOpts do the same as ParseFS(), but applies given options, like
+// WithDelims or DisallowEmpty, to every parsed file.
+func ParseFSOpts(
+	tpl *template.Template, fsys fs.FS, patterns []string, opts ...ParseOption,
+) (*template.Template, error) {
+	filenames := []string{}
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("template: pattern matches no files: %#q", pattern)
+		}
+		filenames = append(filenames, matches...)
+	}
+
+	filenames, err := excludeFiles(
+		filenames, newParseConfig(opts).excludes, path.Match, path.Base,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("template: all files matching %q are excluded", patterns)
+	}
+
+	return parseFiles(tpl, filenames, readFS(fsys), func(filename string) (string, error) {
+		return path.Base(filename), nil
+	}, opts)
+}
+
+// ParseFSTree walks fsys starting from root and parses every file with
+// extension ext (like `.tpl`) into tpl. Templates are named after paths
+// of files relative to root, so `root/emails/welcome.tpl` is referenced as
+// `{{template "emails/welcome.tpl"}}`.
+//
+// It is intended to be used with embed.FS to ship nested template trees
+// inside a binary.
+func ParseFSTree(
+	tpl *template.Template, fsys fs.FS, root, ext string, opts ...ParseOption,
+) (*template.Template, error) {
+	filenames := []string{}
+	err := fs.WalkDir(
+		fsys, root,
+		func(filename string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && path.Ext(filename) == ext {
+				filenames = append(filenames, filename)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf(
+			"template: no files with extension %#q in %#q", ext, root,
+		)
+	}
+	filenames, err = excludeFiles(
+		filenames, newParseConfig(opts).excludes, path.Match, path.Base,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf(
+			"template: all files with extension %#q in %#q are excluded", ext, root,
+		)
+	}
+
+	return parseFiles(tpl, filenames, readFS(fsys), func(filename string) (string, error) {
+		if root == "." {
+			return filename, nil
+		}
+		return strings.TrimPrefix(filename, root+"/"), nil
+	}, opts)
+}
+
+func readFS(fsys fs.FS) func(filename string) ([]byte, error) {
+	return func(filename string) ([]byte, error) {
+		return fs.ReadFile(fsys, filename)
+	}
+}
+
+// StripFS returns filesystem which applies Strip to contents of files with
+// any of given extensions (`.tpl` if none given) when they are read, while
+// other files are passed through untouched. Result can be used with
+// template.ParseFS or any other consumer of fs.FS to get stripped
+// templates without custom parse function:
+//
+//	tpl, err := template.ParseFS(tplutil.StripFS(embedded), "*.tpl")
+//
+// Opened files report size of stripped content, but directory listings
+// still report sizes of original files.
+func StripFS(fsys fs.FS, exts ...string) fs.FS {
+	if len(exts) == 0 {
+		exts = []string{".tpl"}
+	}
+
+	return &stripFS{fsys: fsys, exts: exts}
+}
+
+type stripFS struct {
+	fsys fs.FS
+	exts []string
+}
+
+func (stripped *stripFS) isTemplate(name string) bool {
+	for _, ext := range stripped.exts {
+		if path.Ext(name) == ext {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (stripped *stripFS) Open(name string) (fs.File, error) {
+	file, err := stripped.fsys.Open(name)
+	if err != nil || !stripped.isTemplate(name) {
+		return file, err
+	}
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return file, err
+	}
+
+	defer file.Close()
+
+	buf := &bytes.Buffer{}
+	_, err = buf.ReadFrom(file)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	content := Strip(buf.String())
+
+	return &strippedFile{
+		Reader: strings.NewReader(content),
+		info:   strippedFileInfo{FileInfo: info, size: int64(len(content))},
+	}, nil
+}
+
+func (stripped *stripFS) ReadFile(name string) ([]byte, error) {
+	content, err := fs.ReadFile(stripped.fsys, name)
+	if err != nil || !stripped.isTemplate(name) {
+		return content, err
+	}
+
+	return []byte(Strip(string(content))), nil
+}
+
+type strippedFile struct {
+	*strings.Reader
+	info fs.FileInfo
+}
+
+func (file *strippedFile) Stat() (fs.FileInfo, error) {
+	return file.info, nil
+}
+
+func (file *strippedFile) Close() error {
+	return nil
+}
+
+// strippedFileInfo reports size of stripped content instead of original.
+type strippedFileInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (info strippedFileInfo) Size() int64 {
+	return info.size
+}
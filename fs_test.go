@@ -0,0 +1,198 @@
+package tplutil
+
+import (
+	"embed"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+)
+
+//go:embed testdata/tree
+var treeFS embed.FS
+
+func TestParseFSTree_ResolvesCrossDirectoryReferences(t *testing.T) {
+	tpl, err := ParseFSTree(nil, treeFS, "testdata/tree", ".tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Lookup("emails/notes.txt") != nil {
+		t.Error("file with other extension is loaded")
+	}
+
+	result, err := ExecuteTemplateToString(
+		tpl, "emails/welcome.tpl", map[string]string{"Name": "John"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "Hello, John!" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestParseFSTree_ReturnsErrorWithoutMatchingFiles(t *testing.T) {
+	_, err := ParseFSTree(nil, treeFS, "testdata/tree", ".html")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.tpl": {Data: []byte("\t{{.}}\n\t!")},
+		"b.tpl": {Data: []byte("\t<{{template \"a.tpl\" .}}>")},
+	}
+
+	tpl, err := ParseFS(nil, fsys, "*.tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteTemplateToString(tpl, "b.tpl", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "<x!>" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestParseFSOpts_AppliesOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.tpl":     {Data: []byte("\t{{literal}} <% . %>\n")},
+		"blank.tpl": {Data: []byte(" \n\t\n")},
+	}
+
+	tpl, err := ParseFSOpts(
+		nil, fsys, []string{"a.tpl"}, WithDelims("<%", "%>"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "{{literal}} x" {
+		t.Errorf("unexpected result: %q", result)
+	}
+
+	_, err = ParseFSOpts(
+		nil, fsys, []string{"*.tpl"}, WithDelims("<%", "%>"), DisallowEmpty(),
+	)
+	if err == nil || !strings.Contains(err.Error(), "blank.tpl") {
+		t.Errorf("expected error about blank.tpl, got %v", err)
+	}
+}
+
+func TestParseFS_ReturnsErrorOnNoMatch(t *testing.T) {
+	_, err := ParseFS(nil, fstest.MapFS{}, "*.tpl")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestExclude_AppliesToFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.tpl":           {Data: []byte("a")},
+		"_part.tpl":       {Data: []byte("{{")},
+		"dir/_nested.tpl": {Data: []byte("{{")},
+		"dir/b.tpl":       {Data: []byte("b")},
+	}
+
+	tpl, err := ParseFSOpts(nil, fsys, []string{"*.tpl"}, Exclude("_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Lookup("_part.tpl") != nil {
+		t.Error("excluded file is registered")
+	}
+
+	tpl, err = ParseFSTree(nil, fsys, ".", ".tpl", Exclude("_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Lookup("dir/b.tpl") == nil || tpl.Lookup("dir/_nested.tpl") != nil {
+		t.Errorf("unexpected templates: %s", tpl.DefinedTemplates())
+	}
+}
+
+func TestStripFS(t *testing.T) {
+	fsys := StripFS(fstest.MapFS{
+		"a.tpl":    {Data: []byte("\t<b>\n\t\t{{.}}\n\t</b>\n")},
+		"note.txt": {Data: []byte("\tkept\n\tas is\n")},
+	})
+
+	testcases := map[string]string{
+		"a.tpl":    "<b>{{.}}</b>",
+		"note.txt": "\tkept\n\tas is\n",
+	}
+
+	for name, expected := range testcases {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, content)
+		}
+
+		file, err := fsys.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err = io.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		file.Close()
+
+		if string(content) != expected {
+			t.Errorf("%s: opened: expected %q, got %q", name, expected, content)
+		}
+
+		if info.Size() != int64(len(expected)) {
+			t.Errorf(
+				"%s: expected size %d, got %d", name, len(expected), info.Size(),
+			)
+		}
+	}
+}
+
+func TestStripFS_WorksWithTemplateParseFS(t *testing.T) {
+	fsys := StripFS(fstest.MapFS{
+		"a.html": {Data: []byte("\t<p>\n\t\t{{.}}\n\t</p>\n")},
+	}, ".html")
+
+	tpl, err := template.ParseFS(fsys, "*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "<p>x</p>" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
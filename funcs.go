@@ -0,0 +1,58 @@
+package tplutil
+
+import (
+	"sync"
+	"text/template"
+)
+
+var defaultFuncs = struct {
+	sync.RWMutex
+	funcs template.FuncMap
+}{funcs: template.FuncMap{}}
+
+// Default combines general purpose FuncMaps of the package: Last, First,
+// Strings, Lists, Values, Math, Path, URL, JSON, Regexp and Time. Maps
+// which depend on environment or are needed only occasionally are not
+// included and should be added explicitly.
+var Default = mergeFuncMaps(
+	Last, First, Strings, Lists, Values, Math, Path, URL, JSON, Regexp, Time,
+)
+
+// mergeFuncMaps returns new FuncMap with functions of all maps. Functions
+// of later maps override functions of earlier ones.
+func mergeFuncMaps(maps ...template.FuncMap) template.FuncMap {
+	merged := template.FuncMap{}
+	for _, funcs := range maps {
+		for name, fn := range funcs {
+			merged[name] = fn
+		}
+	}
+
+	return merged
+}
+
+// SetDefaultFuncs sets functions which are added to every template created
+// by parse functions of the package and SetBuilder, so helpers can be
+// configured once for the whole program:
+//
+//	func init() {
+//		tplutil.SetDefaultFuncs(tplutil.Default)
+//	}
+//
+// Templates passed to parse functions by caller are left as is. Since
+// functions are resolved at parse time, it must be called before any
+// parsing.
+func SetDefaultFuncs(funcs template.FuncMap) {
+	defaultFuncs.Lock()
+	defer defaultFuncs.Unlock()
+
+	defaultFuncs.funcs = mergeFuncMaps(funcs)
+}
+
+// newTemplate creates new template with default functions.
+func newTemplate(name string) *template.Template {
+	defaultFuncs.RLock()
+	defer defaultFuncs.RUnlock()
+
+	return template.New(name).Funcs(defaultFuncs.funcs)
+}
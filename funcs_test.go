@@ -0,0 +1,55 @@
+package tplutil
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestSetDefaultFuncs(t *testing.T) {
+	SetDefaultFuncs(template.FuncMap{"shout": strings.ToUpper})
+	defer SetDefaultFuncs(nil)
+
+	tpl, err := ParseString("main", `{{shout .}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "HELLO" {
+		t.Errorf("expected %q, got %q", "HELLO", result)
+	}
+
+	_, err = ParseText(template.New("main"), `{{shout .}}`)
+	if err == nil {
+		t.Error("expected template passed by caller to be left as is")
+	}
+}
+
+func TestSetDefaultFuncs_NilRemovesFuncs(t *testing.T) {
+	SetDefaultFuncs(template.FuncMap{"shout": strings.ToUpper})
+	SetDefaultFuncs(nil)
+
+	_, err := ParseString("main", `{{shout .}}`)
+	if err == nil {
+		t.Error("expected error on function removed from defaults")
+	}
+}
+
+func TestSetDefaultFuncs_CopiesFuncs(t *testing.T) {
+	funcs := template.FuncMap{"shout": strings.ToUpper}
+
+	SetDefaultFuncs(funcs)
+	defer SetDefaultFuncs(nil)
+
+	delete(funcs, "shout")
+
+	_, err := ParseString("main", `{{shout .}}`)
+	if err != nil {
+		t.Errorf("defaults are changed by modification of passed map: %s", err)
+	}
+}
@@ -0,0 +1,27 @@
+package tplutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"text/template"
+)
+
+// Hash provides digest and encoding functions, which are handy for
+// cache-busting suffixes of generated artifacts:
+//
+//	{{sha256sum .Content}}   // hex-encoded SHA-256 digest
+//	{{hexEncode .Content}}   // hex-encoded bytes of the string
+var Hash = template.FuncMap{
+	"sha256sum": sha256sum,
+	"hexEncode": hexEncode,
+}
+
+func sha256sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hexEncode(s string) string {
+	return hex.EncodeToString([]byte(s))
+}
@@ -0,0 +1,36 @@
+package tplutil
+
+import (
+	"testing"
+)
+
+func TestSha256sum(t *testing.T) {
+	testcases := map[string]string{
+		"":    "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"abc": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq": "248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1",
+	}
+
+	for text, expected := range testcases {
+		result := sha256sum(text)
+		if result != expected {
+			t.Errorf("%q: expected %s, got %s", text, expected, result)
+		}
+	}
+}
+
+func TestHexEncode(t *testing.T) {
+	testcases := map[string]string{
+		"":       "",
+		"abc":    "616263",
+		"\x00\n": "000a",
+		"я":      "d18f",
+	}
+
+	for text, expected := range testcases {
+		result := hexEncode(text)
+		if result != expected {
+			t.Errorf("%q: expected %s, got %s", text, expected, result)
+		}
+	}
+}
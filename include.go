@@ -0,0 +1,52 @@
+package tplutil
+
+import (
+	"text/template"
+)
+
+// Include returns functions which render templates of the set tpl into
+// strings, so their output can be passed to other functions:
+//
+//	{{include "header" . | trimIndent}}
+//	{{macro "button" (dict "label" "OK" "kind" "primary")}}
+//
+// `macro` is a convention for parameterized components: it is the same
+// as `include`, but takes named arguments built with `dict`. It is not
+// named `call` to not shadow built-in function of the same name.
+//
+// `includeStrip` and `macroStrip` variants pass output through Strip, so
+// output of sub-templates with stray indentation, like ones loaded
+// without stripping, comes back collapsed.
+//
+// Functions are bound to tpl, so they should be added to it before
+// parsing:
+//
+//	tpl := template.New("main")
+//	tpl.Funcs(tplutil.Include(tpl))
+//
+// Binding survives Clone, so clones of tpl, including ones returned by
+// ReplaceTemplate and WithFuncs, keep rendering templates of tpl. Parse
+// functions of the package accept WithInclude option, which adds
+// functions bound to the right set instead.
+func Include(tpl *template.Template) template.FuncMap {
+	include := func(name string, data interface{}) (string, error) {
+		return ExecuteTemplateToString(tpl, name, data)
+	}
+
+	includeStrip := func(name string, data interface{}) (string, error) {
+		result, err := include(name, data)
+
+		return Strip(result), err
+	}
+
+	return template.FuncMap{
+		"include":      include,
+		"includeStrip": includeStrip,
+		"macro": func(name string, args map[string]interface{}) (string, error) {
+			return include(name, args)
+		},
+		"macroStrip": func(name string, args map[string]interface{}) (string, error) {
+			return includeStrip(name, args)
+		},
+	}
+}
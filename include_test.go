@@ -0,0 +1,71 @@
+package tplutil
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestMacro_InvokedWithDifferentArguments(t *testing.T) {
+	tpl, err := ParseText(template.New("main").Funcs(Values), `
+		{{define "button"}}
+			[{{.kind}}: {{.label}}]
+		{{end}}
+
+		{{macro "button" (dict "label" "OK" "kind" "primary")}}
+		{{macro "button" (dict "label" "Cancel" "kind" "secondary")}}
+	`, WithInclude())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[primary: OK][secondary: Cancel]"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestMacro_ReturnsErrorOnUndefinedTemplate(t *testing.T) {
+	tpl, err := ParseText(template.New("main").Funcs(Values), `
+		{{macro "button" (dict "label" "OK")}}
+	`, WithInclude())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ExecuteToString(tpl, nil)
+	if err == nil {
+		t.Fatal("expected error on undefined template")
+	}
+}
+
+func TestIncludeStrip_CollapsesIndentation(t *testing.T) {
+	tpl := template.New("main")
+	tpl.Funcs(Include(tpl)).Funcs(Values)
+
+	// sub-template is parsed without stripping, like one loaded by
+	// text/template itself
+	template.Must(tpl.New("card").Parse("\n\t\t<b>\n\t\t\t{{.}}\n\t\t</b>\n"))
+
+	_, err := ParseText(tpl, `
+		{{include "card" "x"}}|{{includeStrip "card" "x"}}|
+		{{macroStrip "card" (dict)}}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\n\t\t<b>\n\t\t\tx\n\t\t</b>\n|<b>x</b>|<b>map[]</b>"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,45 @@
+package tplutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+)
+
+// JSON provides functions to marshal values into JSON:
+//
+//	{{toJson .Config}}         // {"name":"app","port":80}
+//	{{toPrettyJson .Config}}   // same, indented with two spaces
+//
+// Output is byte-stable for the same value: map keys are sorted, slices
+// keep their order and struct fields follow declaration order, so
+// generated files can be safely diffed. HTML characters are not escaped,
+// since output is not meant to be embedded into HTML.
+var JSON = template.FuncMap{
+	"toJson":       toJSON,
+	"toPrettyJson": toPrettyJSON,
+}
+
+func toJSON(v interface{}) (string, error) {
+	return marshalJSON(v, "")
+}
+
+func toPrettyJSON(v interface{}) (string, error) {
+	return marshalJSON(v, "  ")
+}
+
+func marshalJSON(v interface{}, indent string) (string, error) {
+	buf := &bytes.Buffer{}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	err := encoder.Encode(v)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,70 @@
+package tplutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestToJSON_IsByteStable(t *testing.T) {
+	value := map[string]interface{}{}
+	for i := 0; i < 50; i++ {
+		value[fmt.Sprintf("key%d", i)] = map[string]interface{}{
+			"list":   []interface{}{i, "x", map[string]int{"b": 1, "a": 2}},
+			"nested": map[string]interface{}{"z": true, "y": nil},
+		}
+	}
+
+	for _, marshal := range []func(interface{}) (string, error){
+		toJSON, toPrettyJSON,
+	} {
+		first, err := marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 20; i++ {
+			again, err := marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if again != first {
+				t.Fatalf("output differs between runs:\n%s\n%s", first, again)
+			}
+		}
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	value, err := dict("name", "<app>", "ports", []int{80, 443}, "debug", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := toJSON(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"debug":false,"name":"<app>","ports":[80,443]}`
+	if result != expected {
+		t.Errorf("expected %s, got %s", expected, result)
+	}
+
+	result, err = toPrettyJSON(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "{\n  \"debug\": false,\n  \"name\": \"<app>\",\n  \"ports\": [\n    80,\n    443\n  ]\n}"
+	if result != expected {
+		t.Errorf("expected %s, got %s", expected, result)
+	}
+}
+
+func TestToJSON_ReturnsErrorOnUnsupportedValue(t *testing.T) {
+	_, err := toJSON(map[string]interface{}{"f": func() {}})
+	if err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,319 @@
+package tplutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Lists provides functions to work with slices and arrays of any type:
+//
+//	{{range chunk 3 .Items}}      // rows of at most 3 items
+//	{{pluck "Email" .Users}}      // emails of all users
+//	{{list ", " .Tags}}           // a, b, c
+//	{{list "\n" (mapf "- %s" .Tags)}}
+//	{{range prepend "all" .Tags}} // "all" followed by tags
+//	{{range keys .Legend}}        // sorted keys of map
+//	{{range zip .Names .Values}}{{.first}}={{.second}}{{end}}
+//	{{range $state, $n := countBy "State" .Issues}}{{$n}} {{$state}}{{end}}
+var Lists = template.FuncMap{
+	"chunk":   chunk,
+	"pluck":   pluck,
+	"list":    list,
+	"prepend": prependItem,
+	"append":  appendItem,
+	"keys":    keys,
+	"keysCI":  keysCI,
+	"zip":     zip,
+	"groupBy": groupBy,
+	"countBy": countBy,
+	"mapf":    mapf,
+}
+
+// listValue returns reflected coll if it is a slice or an array.
+func listValue(coll interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(coll)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		return value, nil
+	default:
+		return value, fmt.Errorf("expected slice or array, got %T", coll)
+	}
+}
+
+// chunk splits coll into consecutive sub-slices of size elements. Last
+// sub-slice can be shorter.
+func chunk(size int, coll interface{}) ([][]interface{}, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk: size must be positive, got %d", size)
+	}
+
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("chunk: %s", err)
+	}
+
+	chunks := [][]interface{}{}
+	for i := 0; i < value.Len(); i += size {
+		end := i + size
+		if end > value.Len() {
+			end = value.Len()
+		}
+
+		items := make([]interface{}, 0, end-i)
+		for j := i; j < end; j++ {
+			items = append(items, value.Index(j).Interface())
+		}
+
+		chunks = append(chunks, items)
+	}
+
+	return chunks, nil
+}
+
+// fieldOf returns value of exported field of struct or value by key of
+// map with string keys. Pointers and interfaces are dereferenced.
+func fieldOf(item reflect.Value, field string) (interface{}, error) {
+	for item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return nil, fmt.Errorf("can't get field %q of nil", field)
+		}
+		item = item.Elem()
+	}
+
+	switch item.Kind() {
+	case reflect.Struct:
+		value := item.FieldByName(field)
+		if !value.IsValid() || !value.CanInterface() {
+			return nil, fmt.Errorf(
+				"%s has no exported field %q", item.Type(), field,
+			)
+		}
+		return value.Interface(), nil
+
+	case reflect.Map:
+		if item.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%s is not keyed by string", item.Type())
+		}
+		value := item.MapIndex(reflect.ValueOf(field).Convert(item.Type().Key()))
+		if !value.IsValid() {
+			return nil, fmt.Errorf("%s has no key %q", item.Type(), field)
+		}
+		return value.Interface(), nil
+
+	default:
+		return nil, fmt.Errorf("can't get field %q of %s", field, item.Type())
+	}
+}
+
+// pluck returns values of field of every element of coll. Elements can be
+// structs or maps with string keys.
+func pluck(field string, coll interface{}) ([]interface{}, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("pluck: %s", err)
+	}
+
+	values := make([]interface{}, value.Len())
+	for i := range values {
+		values[i], err = fieldOf(value.Index(i), field)
+		if err != nil {
+			return nil, fmt.Errorf("pluck: element %d: %s", i, err)
+		}
+	}
+
+	return values, nil
+}
+
+// list formats every element of coll with %v and joins them with sep. It
+// covers the simplest case of `last` usage, when no custom rendering of
+// elements is required.
+func list(sep string, coll interface{}) (string, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return "", fmt.Errorf("list: %s", err)
+	}
+
+	items := make([]string, value.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(value.Index(i).Interface())
+	}
+
+	return strings.Join(items, sep), nil
+}
+
+// mapf formats every element of coll with format, like fmt.Sprintf does
+// with element as a single argument.
+func mapf(format string, coll interface{}) ([]string, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("mapf: %s", err)
+	}
+
+	items := make([]string, value.Len())
+	for i := range items {
+		items[i] = fmt.Sprintf(format, value.Index(i).Interface())
+	}
+
+	return items, nil
+}
+
+// listItems copies elements of coll into new slice. Nil coll is treated
+// as empty.
+func listItems(coll interface{}) ([]interface{}, error) {
+	if coll == nil {
+		return []interface{}{}, nil
+	}
+
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, value.Len(), value.Len()+1)
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+
+	return items, nil
+}
+
+// prependItem returns new slice with item followed by elements of coll.
+func prependItem(item, coll interface{}) ([]interface{}, error) {
+	items, err := listItems(coll)
+	if err != nil {
+		return nil, fmt.Errorf("prepend: %s", err)
+	}
+
+	return append([]interface{}{item}, items...), nil
+}
+
+// appendItem returns new slice with elements of coll followed by item.
+func appendItem(item, coll interface{}) ([]interface{}, error) {
+	items, err := listItems(coll)
+	if err != nil {
+		return nil, fmt.Errorf("append: %s", err)
+	}
+
+	return append(items, item), nil
+}
+
+// mapKeys returns keys of map m formatted with %v.
+func mapKeys(m interface{}) ([]string, error) {
+	value := reflect.ValueOf(m)
+	if value.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected map, got %T", m)
+	}
+
+	names := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		names = append(names, fmt.Sprint(key.Interface()))
+	}
+
+	return names, nil
+}
+
+// keys returns sorted keys of map m.
+func keys(m interface{}) ([]string, error) {
+	names, err := mapKeys(m)
+	if err != nil {
+		return nil, fmt.Errorf("keys: %s", err)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// keysCI returns keys of map m sorted case-insensitively, so `Apple` goes
+// before `banana`. Keys keep their original case. Keys equal regardless
+// of case are ordered case-sensitively to keep output stable.
+func keysCI(m interface{}) ([]string, error) {
+	names, err := mapKeys(m)
+	if err != nil {
+		return nil, fmt.Errorf("keysCI: %s", err)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		left, right := strings.ToLower(names[i]), strings.ToLower(names[j])
+		if left == right {
+			return names[i] < names[j]
+		}
+		return left < right
+	})
+
+	return names, nil
+}
+
+// zip pairs elements of a and b with the same index into maps with keys
+// `first` and `second`. Result is as long as the shorter of a and b.
+func zip(a, b interface{}) ([]map[string]interface{}, error) {
+	first, err := listValue(a)
+	if err != nil {
+		return nil, fmt.Errorf("zip: %s", err)
+	}
+
+	second, err := listValue(b)
+	if err != nil {
+		return nil, fmt.Errorf("zip: %s", err)
+	}
+
+	length := first.Len()
+	if second.Len() < length {
+		length = second.Len()
+	}
+
+	pairs := make([]map[string]interface{}, length)
+	for i := range pairs {
+		pairs[i] = map[string]interface{}{
+			"first":  first.Index(i).Interface(),
+			"second": second.Index(i).Interface(),
+		}
+	}
+
+	return pairs, nil
+}
+
+// groupBy buckets elements of coll by value of field formatted with %v.
+// Elements in every bucket keep their order in coll.
+func groupBy(field string, coll interface{}) (map[string][]interface{}, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("groupBy: %s", err)
+	}
+
+	groups := map[string][]interface{}{}
+	for i := 0; i < value.Len(); i++ {
+		key, err := fieldOf(value.Index(i), field)
+		if err != nil {
+			return nil, fmt.Errorf("groupBy: element %d: %s", i, err)
+		}
+
+		name := fmt.Sprint(key)
+		groups[name] = append(groups[name], value.Index(i).Interface())
+	}
+
+	return groups, nil
+}
+
+// countBy counts elements of coll by value of field formatted with %v.
+func countBy(field string, coll interface{}) (map[string]int, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("countBy: %s", err)
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < value.Len(); i++ {
+		key, err := fieldOf(value.Index(i), field)
+		if err != nil {
+			return nil, fmt.Errorf("countBy: element %d: %s", i, err)
+		}
+
+		counts[fmt.Sprint(key)]++
+	}
+
+	return counts, nil
+}
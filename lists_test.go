@@ -0,0 +1,413 @@
+package tplutil
+
+import (
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+func TestChunk(t *testing.T) {
+	testcases := []struct {
+		size     int
+		coll     interface{}
+		expected [][]interface{}
+	}{
+		{2, []int{1, 2, 3, 4}, [][]interface{}{{1, 2}, {3, 4}}},
+		{3, []int{1, 2, 3, 4}, [][]interface{}{{1, 2, 3}, {4}}},
+		{5, [2]string{"a", "b"}, [][]interface{}{{"a", "b"}}},
+		{1, []string{}, [][]interface{}{}},
+	}
+
+	for _, testcase := range testcases {
+		result, err := chunk(testcase.size, testcase.coll)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result, testcase.expected) {
+			t.Errorf(
+				"chunk %d %v: expected %v, got %v",
+				testcase.size, testcase.coll, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestChunk_ReturnsErrorOnInvalidArguments(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		_, err := chunk(size, []int{1})
+		if err == nil {
+			t.Errorf("size %d: expected error", size)
+		}
+	}
+
+	_, err := chunk(1, 42)
+	if err == nil {
+		t.Error("expected error for non-list argument")
+	}
+}
+
+type user struct {
+	Name  string
+	Email string
+	admin bool
+}
+
+func TestPluck(t *testing.T) {
+	users := []user{
+		{Name: "John", Email: "john@example.com"},
+		{Name: "Jane", Email: "jane@example.com"},
+	}
+
+	testcases := []struct {
+		coll     interface{}
+		expected []interface{}
+	}{
+		{users, []interface{}{"john@example.com", "jane@example.com"}},
+		{[]*user{&users[0]}, []interface{}{"john@example.com"}},
+		{
+			[]map[string]interface{}{{"Email": "a@b.c"}, {"Email": 1}},
+			[]interface{}{"a@b.c", 1},
+		},
+		{
+			[]interface{}{users[1], map[string]string{"Email": "x"}},
+			[]interface{}{"jane@example.com", "x"},
+		},
+		{[]user{}, []interface{}{}},
+	}
+
+	for _, testcase := range testcases {
+		result, err := pluck("Email", testcase.coll)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result, testcase.expected) {
+			t.Errorf(
+				"%v: expected %v, got %v",
+				testcase.coll, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestPluck_ReturnsErrorOnMissingField(t *testing.T) {
+	testcases := []interface{}{
+		[]user{{}},
+		[]map[string]interface{}{{"Name": "x"}},
+		[]map[int]string{{1: "x"}},
+		[]*user{nil},
+		[]int{1},
+		42,
+	}
+
+	for _, coll := range testcases {
+		_, err := pluck("Missing", coll)
+		if err == nil {
+			t.Errorf("%#v: expected error", coll)
+		}
+	}
+
+	_, err := pluck("admin", []user{{}})
+	if err == nil {
+		t.Error("expected error for unexported field")
+	}
+}
+
+func TestList(t *testing.T) {
+	testcases := []struct {
+		sep      string
+		coll     interface{}
+		expected string
+	}{
+		{", ", []string{"a", "b", "c"}, "a, b, c"},
+		{"-", []int{1, 2}, "1-2"},
+		{" ", [3]float64{1.5, 2, -3}, "1.5 2 -3"},
+		{", ", []interface{}{1, "x", nil, true}, "1, x, <nil>, true"},
+		{", ", []string{}, ""},
+		{", ", []string{"only"}, "only"},
+	}
+
+	for _, testcase := range testcases {
+		result, err := list(testcase.sep, testcase.coll)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"%v: expected %q, got %q",
+				testcase.coll, testcase.expected, result,
+			)
+		}
+	}
+
+	_, err := list(", ", "abc")
+	if err == nil {
+		t.Error("expected error for non-list argument")
+	}
+}
+
+func TestPrependAppend(t *testing.T) {
+	source := []string{"b", "c"}
+
+	prepended, err := prependItem("a", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appended, err := appendItem("d", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(prepended, []interface{}{"a", "b", "c"}) {
+		t.Errorf("prepend: unexpected result %v", prepended)
+	}
+
+	if !reflect.DeepEqual(appended, []interface{}{"b", "c", "d"}) {
+		t.Errorf("append: unexpected result %v", appended)
+	}
+
+	if !reflect.DeepEqual(source, []string{"b", "c"}) {
+		t.Errorf("source is modified: %v", source)
+	}
+}
+
+func TestPrependAppend_TreatsNilAsEmpty(t *testing.T) {
+	prepended, err := prependItem("a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appended, err := appendItem("a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range [][]interface{}{prepended, appended} {
+		if !reflect.DeepEqual(result, []interface{}{"a"}) {
+			t.Errorf("unexpected result %v", result)
+		}
+	}
+}
+
+func TestPrependAppend_ReturnsErrorOnNonList(t *testing.T) {
+	_, err := prependItem("a", "abc")
+	if err == nil {
+		t.Error("prepend: expected error for non-list argument")
+	}
+
+	_, err = appendItem("a", 42)
+	if err == nil {
+		t.Error("append: expected error for non-list argument")
+	}
+}
+
+func TestKeysCI(t *testing.T) {
+	result, err := keysCI(map[string]int{
+		"banana": 1,
+		"Apple":  2,
+		"cherry": 3,
+		"apple":  4,
+		"Banana": 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"Apple", "apple", "Banana", "banana", "cherry"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	_, err = keysCI([]string{"a"})
+	if err == nil {
+		t.Error("expected error for non-map argument")
+	}
+}
+
+func TestZip(t *testing.T) {
+	testcases := []struct {
+		a, b     interface{}
+		expected []map[string]interface{}
+	}{
+		{
+			[]string{"a", "b"}, []int{1, 2},
+			[]map[string]interface{}{
+				{"first": "a", "second": 1},
+				{"first": "b", "second": 2},
+			},
+		},
+		{
+			[]string{"a", "b", "c"}, []int{1},
+			[]map[string]interface{}{
+				{"first": "a", "second": 1},
+			},
+		},
+		{
+			[]string{"a"}, [2]bool{true, false},
+			[]map[string]interface{}{
+				{"first": "a", "second": true},
+			},
+		},
+		{
+			[]string{}, []int{1, 2},
+			[]map[string]interface{}{},
+		},
+	}
+
+	for _, testcase := range testcases {
+		result, err := zip(testcase.a, testcase.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result, testcase.expected) {
+			t.Errorf(
+				"%v %v: expected %v, got %v",
+				testcase.a, testcase.b, testcase.expected, result,
+			)
+		}
+	}
+
+	_, err := zip([]int{1}, "abc")
+	if err == nil {
+		t.Error("expected error for non-list argument")
+	}
+}
+
+type issueState int
+
+const (
+	issueOpen issueState = iota
+	issueClosed
+)
+
+func (state issueState) String() string {
+	if state == issueClosed {
+		return "closed"
+	}
+	return "open"
+}
+
+type issue struct {
+	ID    int
+	State issueState
+}
+
+func TestGroupByCountBy(t *testing.T) {
+	issues := []issue{
+		{1, issueOpen},
+		{2, issueClosed},
+		{3, issueOpen},
+		{4, issueOpen},
+	}
+
+	groups, err := groupBy("State", issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedGroups := map[string][]interface{}{
+		"open":   {issues[0], issues[2], issues[3]},
+		"closed": {issues[1]},
+	}
+	if !reflect.DeepEqual(groups, expectedGroups) {
+		t.Errorf("groupBy: expected %v, got %v", expectedGroups, groups)
+	}
+
+	counts, err := countBy("State", issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCounts := map[string]int{"open": 3, "closed": 1}
+	if !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("countBy: expected %v, got %v", expectedCounts, counts)
+	}
+}
+
+func TestGroupByCountBy_ReadsMaps(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"kind": "a"}, {"kind": "b"}, {"kind": "a"},
+	}
+
+	counts, err := countBy("kind", rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(counts, map[string]int{"a": 2, "b": 1}) {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+}
+
+func TestGroupByCountBy_ReturnsErrorOnMissingField(t *testing.T) {
+	_, err := groupBy("Missing", []issue{{}})
+	if err == nil {
+		t.Error("groupBy: expected error on missing field")
+	}
+
+	_, err = countBy("Missing", []issue{{}})
+	if err == nil {
+		t.Error("countBy: expected error on missing field")
+	}
+
+	_, err = countBy("State", 42)
+	if err == nil {
+		t.Error("countBy: expected error for non-list argument")
+	}
+}
+
+func TestMapf(t *testing.T) {
+	testcases := []struct {
+		format   string
+		coll     interface{}
+		expected []string
+	}{
+		{"- %s", []string{"a", "b"}, []string{"- a", "- b"}},
+		{"%03d", []int{1, 42}, []string{"001", "042"}},
+		{"%.1f", [2]float64{1.25, 2}, []string{"1.2", "2.0"}},
+		{"%v", []interface{}{nil, true, "x"}, []string{"<nil>", "true", "x"}},
+		{"%s:%s", []string{"a"}, []string{"a:%!s(MISSING)"}},
+		{"- %s", []string{}, []string{}},
+	}
+
+	for _, testcase := range testcases {
+		result, err := mapf(testcase.format, testcase.coll)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result, testcase.expected) {
+			t.Errorf(
+				"%q %v: expected %q, got %q",
+				testcase.format, testcase.coll, testcase.expected, result,
+			)
+		}
+	}
+
+	_, err := mapf("%s", "abc")
+	if err == nil {
+		t.Error("expected error for non-list argument")
+	}
+}
+
+func TestMapf_ComposesWithList(t *testing.T) {
+	tpl, err := ParseText(
+		template.New("main").Funcs(Lists), `{{list "\n" (mapf "- %s" .)}}`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "- a\n- b" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
@@ -0,0 +1,225 @@
+package tplutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"text/template"
+)
+
+// Math provides numeric functions, which accept numbers of any type and
+// numeric strings:
+//
+//	{{if numGt .Score 9.5}}   // works for int Score, unlike built-in gt
+//	{{percent .Done .Total}}  // 67%
+//	{{percentN 1 2 3}}        // 66.7%
+//	{{sum .Amounts}}          // total of int or float slice
+//	{{clamp 0 100 .Progress}} // Progress limited to [0, 100]
+//
+// Arguments are converted to float64 before comparison, so integers
+// larger than 2^53 can lose precision and compare equal when they are
+// not.
+var Math = template.FuncMap{
+	"numGt": numGt,
+	"numLt": numLt,
+	"numEq": numEq,
+
+	"percent":  percent,
+	"percentN": percentN,
+
+	"sum": sum,
+	"avg": avg,
+	"min": minOf,
+	"max": maxOf,
+
+	"abs":    abs,
+	"signum": signum,
+	"clamp":  clamp,
+}
+
+// toNumbers converts a and b to float64 for function named name.
+func toNumbers(name string, a, b interface{}) (float64, float64, error) {
+	x, err := toNumber(a)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %s", name, err)
+	}
+
+	y, err := toNumber(b)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %s", name, err)
+	}
+
+	return x, y, nil
+}
+
+func numGt(a, b interface{}) (bool, error) {
+	x, y, err := toNumbers("numGt", a, b)
+
+	return x > y, err
+}
+
+func numLt(a, b interface{}) (bool, error) {
+	x, y, err := toNumbers("numLt", a, b)
+
+	return x < y, err
+}
+
+func numEq(a, b interface{}) (bool, error) {
+	x, y, err := toNumbers("numEq", a, b)
+
+	return x == y, err
+}
+
+// percent formats part of whole as percentage rounded to integer.
+func percent(part, whole interface{}) (string, error) {
+	return percentN(0, part, whole)
+}
+
+// percentN formats part of whole as percentage with prec digits after
+// decimal point. Zero whole is an error, since ratio is undefined.
+func percentN(prec int, part, whole interface{}) (string, error) {
+	if prec < 0 {
+		return "", fmt.Errorf("percentN: negative precision %d", prec)
+	}
+
+	x, y, err := toNumbers("percent", part, whole)
+	if err != nil {
+		return "", err
+	}
+
+	if y == 0 {
+		return "", fmt.Errorf("percent: whole is zero")
+	}
+
+	return strconv.FormatFloat(x/y*100, 'f', prec, 64) + "%", nil
+}
+
+// numbers converts every element of coll to float64 for function named
+// name.
+func numbers(name string, coll interface{}) ([]float64, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	result := make([]float64, value.Len())
+	for i := range result {
+		result[i], err = toNumber(value.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%s: element %d: %s", name, i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// sum returns sum of numbers in coll, which is 0 for empty coll.
+func sum(coll interface{}) (float64, error) {
+	values, err := numbers("sum", coll)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+
+	return total, nil
+}
+
+// avg returns arithmetic mean of numbers in coll. Empty coll is an error.
+func avg(coll interface{}) (float64, error) {
+	values, err := numbers("avg", coll)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf("avg: empty list")
+	}
+
+	total, _ := sum(values)
+
+	return total / float64(len(values)), nil
+}
+
+// minOf returns the smallest number in coll. Empty coll is an error.
+func minOf(coll interface{}) (float64, error) {
+	return extremum("min", coll, func(a, b float64) bool { return a < b })
+}
+
+// maxOf returns the largest number in coll. Empty coll is an error.
+func maxOf(coll interface{}) (float64, error) {
+	return extremum("max", coll, func(a, b float64) bool { return a > b })
+}
+
+func extremum(
+	name string, coll interface{}, better func(a, b float64) bool,
+) (float64, error) {
+	values, err := numbers(name, coll)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%s: empty list", name)
+	}
+
+	result := values[0]
+	for _, value := range values[1:] {
+		if better(value, result) {
+			result = value
+		}
+	}
+
+	return result, nil
+}
+
+// abs returns absolute value of n.
+func abs(n interface{}) (float64, error) {
+	x, err := toNumber(n)
+	if err != nil {
+		return 0, fmt.Errorf("abs: %s", err)
+	}
+
+	return math.Abs(x), nil
+}
+
+// signum returns -1 for negative n, 1 for positive n and 0 for zero.
+func signum(n interface{}) (int, error) {
+	x, err := toNumber(n)
+	if err != nil {
+		return 0, fmt.Errorf("signum: %s", err)
+	}
+
+	switch {
+	case x < 0:
+		return -1, nil
+	case x > 0:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// clamp limits n to range from min to max inclusive. Min greater than max
+// is an error rather than silently swapped bounds, since it usually means
+// arguments are passed in wrong order.
+func clamp(min, max, n interface{}) (float64, error) {
+	low, high, err := toNumbers("clamp", min, max)
+	if err != nil {
+		return 0, err
+	}
+
+	if low > high {
+		return 0, fmt.Errorf("clamp: min %v is greater than max %v", min, max)
+	}
+
+	x, err := toNumber(n)
+	if err != nil {
+		return 0, fmt.Errorf("clamp: %s", err)
+	}
+
+	return math.Min(math.Max(x, low), high), nil
+}
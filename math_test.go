@@ -0,0 +1,298 @@
+package tplutil
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestNumComparisons(t *testing.T) {
+	testcases := []struct {
+		a, b       interface{}
+		gt, lt, eq bool
+	}{
+		{3, 2.5, true, false, false},
+		{2.5, 3, false, true, false},
+		{3, 3.0, false, false, true},
+		{int64(-1), uint8(1), false, true, false},
+		{"10", 9, true, false, false},
+	}
+
+	for _, testcase := range testcases {
+		results := map[string]func(a, b interface{}) (bool, error){
+			"numGt": numGt,
+			"numLt": numLt,
+			"numEq": numEq,
+		}
+		expected := map[string]bool{
+			"numGt": testcase.gt,
+			"numLt": testcase.lt,
+			"numEq": testcase.eq,
+		}
+
+		for name, fn := range results {
+			result, err := fn(testcase.a, testcase.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != expected[name] {
+				t.Errorf(
+					"%s %#v %#v: expected %t, got %t",
+					name, testcase.a, testcase.b, expected[name], result,
+				)
+			}
+		}
+	}
+
+	_, err := numGt("abc", 1)
+	if err == nil {
+		t.Error("expected error for non-numeric argument")
+	}
+}
+
+func TestNumComparisons_AcceptMixedTypesRejectedByBuiltins(t *testing.T) {
+	data := map[string]interface{}{"Score": 10, "Limit": 9.5}
+
+	builtin := template.Must(template.New("builtin").Parse(`{{gt .Score .Limit}}`))
+
+	_, err := ExecuteToString(builtin, data)
+	if err == nil {
+		t.Fatal("expected built-in gt to reject int and float64")
+	}
+
+	tpl, err := ParseText(
+		template.New("main").Funcs(Math), `{{numGt .Score .Limit}}`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "true" {
+		t.Errorf("expected %q, got %q", "true", result)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	testcases := []struct {
+		prec        int
+		part, whole interface{}
+		expected    string
+	}{
+		{0, 1, 2, "50%"},
+		{0, 2, 3, "67%"},
+		{1, 2, 3, "66.7%"},
+		{2, 1, 3, "33.33%"},
+		{0, 0, 5, "0%"},
+		{1, 3, 2, "150.0%"},
+		{0, 0.25, 1, "25%"},
+		{0, -1, 4, "-25%"},
+	}
+
+	for _, testcase := range testcases {
+		result, err := percentN(testcase.prec, testcase.part, testcase.whole)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"percentN %d %v %v: expected %q, got %q",
+				testcase.prec, testcase.part, testcase.whole,
+				testcase.expected, result,
+			)
+		}
+	}
+
+	result, err := percent(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "67%" {
+		t.Errorf("percent 2 3: expected %q, got %q", "67%", result)
+	}
+}
+
+func TestPercent_ReturnsErrorOnInvalidArguments(t *testing.T) {
+	_, err := percent(1, 0)
+	if err == nil {
+		t.Error("expected error on zero whole")
+	}
+
+	_, err = percentN(-1, 1, 2)
+	if err == nil {
+		t.Error("expected error on negative precision")
+	}
+
+	_, err = percent("abc", 2)
+	if err == nil {
+		t.Error("expected error on non-numeric argument")
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	testcases := []struct {
+		coll               interface{}
+		sum, avg, min, max float64
+	}{
+		{[]interface{}{1, 2.5, "3", int64(-4)}, 2.5, 0.625, -4, 3},
+		{[]int{7}, 7, 7, 7, 7},
+		{[2]float64{0.5, 1.5}, 2, 1, 0.5, 1.5},
+	}
+
+	for _, testcase := range testcases {
+		expected := map[string]float64{
+			"sum": testcase.sum,
+			"avg": testcase.avg,
+			"min": testcase.min,
+			"max": testcase.max,
+		}
+
+		for name, fn := range map[string]func(interface{}) (float64, error){
+			"sum": sum,
+			"avg": avg,
+			"min": minOf,
+			"max": maxOf,
+		} {
+			result, err := fn(testcase.coll)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != expected[name] {
+				t.Errorf(
+					"%s %v: expected %v, got %v",
+					name, testcase.coll, expected[name], result,
+				)
+			}
+		}
+	}
+}
+
+func TestAggregates_EmptyList(t *testing.T) {
+	result, err := sum([]int{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != 0 {
+		t.Errorf("sum: expected 0, got %v", result)
+	}
+
+	for name, fn := range map[string]func(interface{}) (float64, error){
+		"avg": avg,
+		"min": minOf,
+		"max": maxOf,
+	} {
+		_, err := fn([]int{})
+		if err == nil {
+			t.Errorf("%s: expected error on empty list", name)
+		}
+	}
+}
+
+func TestAggregates_ReturnsErrorOnInvalidArguments(t *testing.T) {
+	for name, fn := range map[string]func(interface{}) (float64, error){
+		"sum": sum,
+		"avg": avg,
+		"min": minOf,
+		"max": maxOf,
+	} {
+		_, err := fn(42)
+		if err == nil {
+			t.Errorf("%s: expected error for non-list argument", name)
+		}
+
+		_, err = fn([]interface{}{1, "abc"})
+		if err == nil || !strings.Contains(err.Error(), "element 1") {
+			t.Errorf("%s: expected error about element 1, got %v", name, err)
+		}
+	}
+}
+
+func TestAbsSignum(t *testing.T) {
+	testcases := []struct {
+		n      interface{}
+		abs    float64
+		signum int
+	}{
+		{-3, 3, -1},
+		{-0.5, 0.5, -1},
+		{0, 0, 0},
+		{0.0, 0, 0},
+		{uint(7), 7, 1},
+		{"2.5", 2.5, 1},
+	}
+
+	for _, testcase := range testcases {
+		result, err := abs(testcase.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != testcase.abs {
+			t.Errorf("abs %#v: expected %v, got %v", testcase.n, testcase.abs, result)
+		}
+
+		sign, err := signum(testcase.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sign != testcase.signum {
+			t.Errorf(
+				"signum %#v: expected %d, got %d", testcase.n, testcase.signum, sign,
+			)
+		}
+	}
+
+	_, err := abs("abc")
+	if err == nil {
+		t.Error("abs: expected error on non-numeric argument")
+	}
+
+	_, err = signum(nil)
+	if err == nil {
+		t.Error("signum: expected error on non-numeric argument")
+	}
+}
+
+func TestClamp(t *testing.T) {
+	testcases := []struct {
+		min, max, n interface{}
+		expected    float64
+	}{
+		{0, 100, 50, 50},
+		{0, 100, -5, 0},
+		{0, 100, 150.5, 100},
+		{0, 100, 0, 0},
+		{0, 100, 100, 100},
+		{-1.5, -0.5, -1, -1},
+		{5, 5, 3, 5},
+	}
+
+	for _, testcase := range testcases {
+		result, err := clamp(testcase.min, testcase.max, testcase.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"clamp %v %v %v: expected %v, got %v",
+				testcase.min, testcase.max, testcase.n, testcase.expected, result,
+			)
+		}
+	}
+
+	_, err := clamp(100, 0, 50)
+	if err == nil {
+		t.Error("expected error when min is greater than max")
+	}
+}
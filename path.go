@@ -0,0 +1,22 @@
+package tplutil
+
+import (
+	"path"
+	"text/template"
+)
+
+// Path provides functions to manipulate slash-separated paths:
+//
+//	{{base "src/main.go"}}   // main.go
+//	{{dir "src/main.go"}}    // src
+//	{{ext "src/main.go"}}    // .go
+//
+// Functions use package path rather than path/filepath, so output does
+// not depend on OS the template is rendered on. Generated paths, URLs and
+// import statements use forward slashes anyway. Edge cases are handled
+// as in package path: `base "a/b/"` is `b`, while `dir "a/b/"` is `a/b`.
+var Path = template.FuncMap{
+	"base": path.Base,
+	"dir":  path.Dir,
+	"ext":  path.Ext,
+}
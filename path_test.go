@@ -0,0 +1,44 @@
+package tplutil
+
+import (
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	testcases := []struct {
+		path string
+		base string
+		dir  string
+		ext  string
+	}{
+		{"src/main.go", "main.go", "src", ".go"},
+		{"/usr/lib/libc.so.6", "libc.so.6", "/usr/lib", ".6"},
+		{"a/b/", "b", "a/b", ""},
+		{"Makefile", "Makefile", ".", ""},
+		{"dir/.gitignore", ".gitignore", "dir", ".gitignore"},
+		{"", ".", ".", ""},
+		{"/", "/", "/", ""},
+	}
+
+	funcs := map[string]func(string) string{
+		"base": Path["base"].(func(string) string),
+		"dir":  Path["dir"].(func(string) string),
+		"ext":  Path["ext"].(func(string) string),
+	}
+
+	for _, testcase := range testcases {
+		for name, expected := range map[string]string{
+			"base": testcase.base,
+			"dir":  testcase.dir,
+			"ext":  testcase.ext,
+		} {
+			result := funcs[name](testcase.path)
+			if result != expected {
+				t.Errorf(
+					"%s %q: expected %q, got %q",
+					name, testcase.path, expected, result,
+				)
+			}
+		}
+	}
+}
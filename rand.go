@@ -0,0 +1,79 @@
+package tplutil
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"text/template"
+	"time"
+)
+
+var random = struct {
+	sync.Mutex
+	rand *rand.Rand
+}{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// Rand provides functions to pick random data, which is handy for sample
+// and placeholder output:
+//
+//	{{randItem .Names}}
+//	{{range shuffle .Items}}{{.}}{{end}}
+//
+// Output is different on every run unless fixed source is set with
+// SetRandSource, so it is not included in Default.
+var Rand = template.FuncMap{
+	"randItem": randItem,
+	"shuffle":  shuffle,
+}
+
+// SetRandSource sets source of randomness for Rand functions, which is
+// useful to get deterministic output in tests:
+//
+//	tplutil.SetRandSource(rand.NewSource(1))
+//
+// Passing nil restores default source seeded with current time.
+func SetRandSource(source rand.Source) {
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+
+	random.Lock()
+	defer random.Unlock()
+
+	random.rand = rand.New(source)
+}
+
+// randItem returns random element of coll. Empty coll is an error.
+func randItem(coll interface{}) (interface{}, error) {
+	value, err := listValue(coll)
+	if err != nil {
+		return nil, fmt.Errorf("randItem: %s", err)
+	}
+
+	if value.Len() == 0 {
+		return nil, fmt.Errorf("randItem: empty list")
+	}
+
+	random.Lock()
+	defer random.Unlock()
+
+	return value.Index(random.rand.Intn(value.Len())).Interface(), nil
+}
+
+// shuffle returns new slice with elements of coll in random order. coll
+// itself is not modified.
+func shuffle(coll interface{}) ([]interface{}, error) {
+	items, err := listItems(coll)
+	if err != nil {
+		return nil, fmt.Errorf("shuffle: %s", err)
+	}
+
+	random.Lock()
+	defer random.Unlock()
+
+	random.rand.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	return items, nil
+}
@@ -0,0 +1,74 @@
+package tplutil
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestShuffle_IsDeterministicWithFixedSource(t *testing.T) {
+	defer SetRandSource(nil)
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	SetRandSource(rand.NewSource(1))
+	first, err := shuffle(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetRandSource(rand.NewSource(1))
+	second, err := shuffle(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("shuffles with the same seed differ: %v and %v", first, second)
+	}
+
+	if !reflect.DeepEqual(items, []int{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("source slice is modified: %v", items)
+	}
+
+	if len(first) != len(items) {
+		t.Errorf("unexpected shuffled slice: %v", first)
+	}
+}
+
+func TestRandItem(t *testing.T) {
+	defer SetRandSource(nil)
+
+	SetRandSource(rand.NewSource(1))
+
+	items := []string{"a", "b", "c"}
+	for i := 0; i < 10; i++ {
+		item, err := randItem(items)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if item != "a" && item != "b" && item != "c" {
+			t.Errorf("unexpected item: %v", item)
+		}
+	}
+
+	_, err := randItem([]string{})
+	if err == nil {
+		t.Error("expected error for empty list")
+	}
+}
+
+func TestSetRandSource_NilRestoresDefault(t *testing.T) {
+	SetRandSource(nil)
+
+	_, err := randItem([]int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = shuffle([]int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
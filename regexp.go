@@ -0,0 +1,60 @@
+package tplutil
+
+import (
+	"regexp"
+	"sync"
+	"text/template"
+)
+
+// regexpCacheSize limits number of cached compiled patterns.
+const regexpCacheSize = 256
+
+var regexpCache = struct {
+	sync.RWMutex
+	compiled map[string]*regexp.Regexp
+}{compiled: map[string]*regexp.Regexp{}}
+
+// Regexp provides regular expression functions:
+//
+//	{{if matches "^v[0-9]" .Tag}}release{{end}}
+//
+// Compiled patterns are cached. Cache holds up to 256 patterns and is
+// emptied when it is full, so patterns built from data don't grow memory
+// unbounded, but they are recompiled often then.
+var Regexp = template.FuncMap{
+	"matches": matches,
+}
+
+// compileRegexp returns cached compiled pattern, compiling it on first use.
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpCache.RLock()
+	compiled, ok := regexpCache.compiled[pattern]
+	regexpCache.RUnlock()
+
+	if ok {
+		return compiled, nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexpCache.Lock()
+	if len(regexpCache.compiled) >= regexpCacheSize {
+		regexpCache.compiled = map[string]*regexp.Regexp{}
+	}
+	regexpCache.compiled[pattern] = compiled
+	regexpCache.Unlock()
+
+	return compiled, nil
+}
+
+func matches(pattern, s string) (bool, error) {
+	compiled, err := compileRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return compiled.MatchString(s), nil
+}
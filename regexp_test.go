@@ -0,0 +1,59 @@
+package tplutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatches(t *testing.T) {
+	testcases := []struct {
+		pattern  string
+		text     string
+		expected bool
+	}{
+		{"^v[0-9]", "v1.2.3", true},
+		{"^v[0-9]", "latest", false},
+		{"", "anything", true},
+	}
+
+	for _, testcase := range testcases {
+		result, err := matches(testcase.pattern, testcase.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"%q =~ %q: expected %v",
+				testcase.text, testcase.pattern, testcase.expected,
+			)
+		}
+	}
+}
+
+func TestMatches_ReturnsErrorOnInvalidPattern(t *testing.T) {
+	_, err := matches("^v[0-9", "v1")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMatches_LimitsCache(t *testing.T) {
+	for i := 0; i < regexpCacheSize*3; i++ {
+		result, err := matches(fmt.Sprintf("^%d$", i), fmt.Sprint(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !result {
+			t.Fatalf("pattern %d doesn't match", i)
+		}
+	}
+
+	regexpCache.RLock()
+	defer regexpCache.RUnlock()
+
+	if len(regexpCache.compiled) > regexpCacheSize {
+		t.Errorf("cache grew to %d patterns", len(regexpCache.compiled))
+	}
+}
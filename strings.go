@@ -0,0 +1,278 @@
+package tplutil
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Strings provides text manipulation functions:
+//
+//	{{pluralize 3 "file" "files"}}   // files
+//	{{humanize "HTTPStatus"}}        // HTTP Status
+//	{{trimIndent .Block}}            // block moved to column 0
+//	{{csvRow .Name .Email}}          // "Doe, John",john@example.com
+//	{{slugify "Hello, World!"}}      // hello-world
+//	{{blank 2}}                      // two newlines, one blank line
+//	{{wordCount .Text}}              // number of words
+//	{{words 10 .Text}}               // first 10 words
+//	{{nospace "a b\tc"}}             // abc
+//	{{titleCase "hello wORLD"}}      // Hello World
+//	{{sentenceCase "hello World"}}   // Hello world
+//	{{cat "v" .Major "." .Minor}}    // v1.2
+//	{{catsp "Hello," .Name}}         // Hello, John
+var Strings = template.FuncMap{
+	"pluralize":  pluralize,
+	"humanize":   humanize,
+	"trimIndent": trimIndent,
+	"csvRow":     csvRow,
+	"slugify":    slugify,
+	"blank":      blank,
+	"wordCount":  wordCount,
+	"words":      words,
+	"nospace":    nospace,
+
+	"titleCase":    titleCase,
+	"sentenceCase": sentenceCase,
+
+	"cat":   cat,
+	"catsp": catsp,
+}
+
+// pluralize returns singular form for count of exactly one (or minus one)
+// and plural form otherwise.
+func pluralize(count int, singular, plural string) string {
+	if count == 1 || count == -1 {
+		return singular
+	}
+
+	return plural
+}
+
+// humanize turns identifier into label by splitting it into words on case
+// boundaries, underscores and dashes, and capitalizing every word:
+// `FirstName` and `first_name` become `First Name`.
+//
+// Run of upper case letters is kept as single acronym word, except for its
+// last letter when it is followed by lower case one, so `HTTPStatus`
+// becomes `HTTP Status`.
+func humanize(s string) string {
+	runes := []rune(s)
+	words := []string{}
+	word := []rune{}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			acronymEnd := unicode.IsUpper(prev) &&
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || acronymEnd {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// trimIndent removes common leading indentation from every line of s,
+// keeping relative indentation intact. Lines consisting only of
+// whitespace do not count when computing common indentation. Tabs and
+// spaces are counted as one character each, so indentation should be
+// consistent across lines.
+func trimIndent(s string) string {
+	lines := strings.Split(s, "\n")
+
+	common := -1
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		if common == -1 || indent < common {
+			common = indent
+		}
+	}
+
+	if common <= 0 {
+		return s
+	}
+
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent > common {
+			indent = common
+		}
+		lines[i] = line[indent:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// csvRow formats fields as single CSV record without trailing newline,
+// quoting fields as described in RFC 4180. Fields are formatted with %v.
+func csvRow(fields ...interface{}) string {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		record[i] = fmt.Sprint(field)
+	}
+
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+
+	// Writing into buffer with default separator can't fail.
+	_ = writer.Write(record)
+	writer.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// slugify makes string safe to use in URLs and file names: it lowercases
+// s and replaces every run of characters other than ASCII letters and
+// digits with single hyphen, trimming hyphens at both ends.
+//
+// Non-ASCII characters are not transliterated and are treated as
+// separators, so `Café au lait` becomes `caf-au-lait`. Transliterate
+// values beforehand if that is not acceptable.
+func slugify(s string) string {
+	buf := &strings.Builder{}
+	hyphen := false
+
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && buf.Len() > 0 {
+				buf.WriteByte('-')
+			}
+			buf.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+
+	return buf.String()
+}
+
+// blank returns n newlines, replacing `{{"\n"}}{{"\n"}}` chains in
+// stripped templates. Negative n is treated as zero.
+func blank(n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	return strings.Repeat("\n", n)
+}
+
+// wordCount returns number of words in s separated by Unicode whitespace.
+func wordCount(s string) int {
+	return len(strings.Fields(s))
+}
+
+// words returns first n words of s joined by single spaces.
+func words(n int, s string) string {
+	fields := strings.Fields(s)
+	if n < 0 {
+		n = 0
+	}
+	if n < len(fields) {
+		fields = fields[:n]
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// nospace removes all Unicode whitespace from s, not only at its ends.
+func nospace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// titleCase capitalizes first letter of every word of s and lowercases the
+// rest, so `hello wORLD` becomes `Hello World`. Words are separated by
+// whitespace and only their first rune is capitalized, so `2nd place`
+// becomes `2nd Place`. Unlike deprecated strings.Title, letters following
+// punctuation inside word, like in `don't`, are not capitalized. Rules
+// specific to language, like in golang.org/x/text/cases, are not applied.
+func titleCase(s string) string {
+	runes := []rune(s)
+	start := true
+
+	for i, r := range runes {
+		switch {
+		case unicode.IsSpace(r):
+			start = true
+		case start:
+			runes[i] = unicode.ToTitle(r)
+			start = false
+		default:
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	return string(runes)
+}
+
+// sentenceCase capitalizes first non-space rune of s and lowercases the
+// rest, so `hello World` becomes `Hello world`, but `2nd Place` becomes
+// `2nd place`. Note that acronyms are lowercased too.
+func sentenceCase(s string) string {
+	runes := []rune(strings.ToLower(s))
+	for i, r := range runes {
+		if !unicode.IsSpace(r) {
+			runes[i] = unicode.ToTitle(r)
+			break
+		}
+	}
+
+	return string(runes)
+}
+
+// cat formats every argument with %v, like toString does, and
+// concatenates results without separator.
+func cat(args ...interface{}) string {
+	return joinArgs("", args)
+}
+
+// catsp does the same as cat, but separates arguments with single space.
+func catsp(args ...interface{}) string {
+	return joinArgs(" ", args)
+}
+
+func joinArgs(sep string, args []interface{}) string {
+	items := make([]string, len(args))
+	for i, arg := range args {
+		items[i] = toString(arg)
+	}
+
+	return strings.Join(items, sep)
+}
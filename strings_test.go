@@ -0,0 +1,257 @@
+package tplutil
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestTitleCase(t *testing.T) {
+	testcases := map[string]string{
+		"hello world":      "Hello World",
+		"hello wORLD":      "Hello World",
+		"HTTP status":      "Http Status",
+		"  two  spaces\t":  "  Two  Spaces\t",
+		"2nd place":        "2nd Place",
+		"don't stop":       "Don't Stop",
+		"(aside) note":     "(aside) Note",
+		"élan vital":       "Élan Vital",
+		"":                 "",
+		"x":                "X",
+		"multi\nline text": "Multi\nLine Text",
+	}
+
+	for text, expected := range testcases {
+		result := titleCase(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestSentenceCase(t *testing.T) {
+	testcases := map[string]string{
+		"hello World":   "Hello world",
+		"HELLO WORLD":   "Hello world",
+		"  leading":     "  Leading",
+		"2nd Place":     "2nd place",
+		"élan Vital":    "Élan vital",
+		"":              "",
+		"already fine.": "Already fine.",
+	}
+
+	for text, expected := range testcases {
+		result := sentenceCase(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	testcases := map[string]string{
+		"FirstName":       "First Name",
+		"firstName":       "First Name",
+		"first_name":      "First Name",
+		"first-name":      "First Name",
+		"HTTPStatus":      "HTTP Status",
+		"HTTPServerError": "HTTP Server Error",
+		"userID":          "User ID",
+		"ID":              "ID",
+		"Base64Encode":    "Base64 Encode",
+		"__private__":     "Private",
+		"":                "",
+	}
+
+	for text, expected := range testcases {
+		result := humanize(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestTrimIndent(t *testing.T) {
+	testcases := map[string]string{
+		"    a\n      b\n    c":      "a\n  b\nc",
+		"\t\tif x {\n\t\t\ty\n\t\t}": "if x {\n\ty\n}",
+		"  a\n\n    b\n":             "a\n\n  b\n",
+		"  a\n \n    b":              "a\n\n  b",
+		"a\n  b":                     "a\n  b",
+		"":                           "",
+		"   \n  ":                    "   \n  ",
+	}
+
+	for text, expected := range testcases {
+		result := trimIndent(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestCsvRow(t *testing.T) {
+	testcases := []struct {
+		fields   []interface{}
+		expected string
+	}{
+		{[]interface{}{"a", "b", 3}, "a,b,3"},
+		{[]interface{}{"Doe, John", "john@example.com"}, `"Doe, John",john@example.com`},
+		{[]interface{}{`say "hi"`, 2.5}, `"say ""hi""",2.5`},
+		{[]interface{}{"multi\nline", ""}, "\"multi\nline\","},
+		{[]interface{}{}, ""},
+	}
+
+	for _, testcase := range testcases {
+		result := csvRow(testcase.fields...)
+		if result != testcase.expected {
+			t.Errorf(
+				"%q: expected %q, got %q",
+				testcase.fields, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	testcases := map[string]string{
+		"Hello, World!":            "hello-world",
+		"multiple   spaces":        "multiple-spaces",
+		"--leading and trailing--": "leading-and-trailing",
+		"  ...dots...  ":           "dots",
+		"Version 2.0 Release":      "version-2-0-release",
+		"Café au lait":             "caf-au-lait",
+		"snake_case_name":          "snake-case-name",
+		"!!!":                      "",
+		"":                         "",
+	}
+
+	for text, expected := range testcases {
+		result := slugify(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestBlank(t *testing.T) {
+	testcases := map[int]string{
+		-1: "",
+		0:  "",
+		1:  "\n",
+		2:  "\n\n",
+	}
+
+	for n, expected := range testcases {
+		result := blank(n)
+		if result != expected {
+			t.Errorf("%d: expected %q, got %q", n, expected, result)
+		}
+	}
+}
+
+func TestBlank_SeparatesStrippedSections(t *testing.T) {
+	tpl, err := ParseText(template.New("main").Funcs(Default), `
+		header
+		{{blank 2}}
+		body
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "header\n\nbody"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	testcases := map[string]int{
+		"":                     0,
+		"   ":                  0,
+		"word":                 1,
+		"  two   words  ":      2,
+		"tabs\tand\nnewlines ": 3,
+	}
+
+	for text, expected := range testcases {
+		result := wordCount(text)
+		if result != expected {
+			t.Errorf("%q: expected %d, got %d", text, expected, result)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	testcases := []struct {
+		n        int
+		text     string
+		expected string
+	}{
+		{2, "", ""},
+		{2, "word", "word"},
+		{2, "  one   two   three  ", "one two"},
+		{5, "one\ttwo\nthree", "one two three"},
+		{0, "one two", ""},
+		{-1, "one two", ""},
+	}
+
+	for _, testcase := range testcases {
+		result := words(testcase.n, testcase.text)
+		if result != testcase.expected {
+			t.Errorf(
+				"%d %q: expected %q, got %q",
+				testcase.n, testcase.text, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestNospace(t *testing.T) {
+	testcases := map[string]string{
+		"":                        "",
+		"  a b\tc\nd  ":           "abcd",
+		"+1 (555) 123-45-67":      "+1(555)123-45-67",
+		"non\u00a0breaking\u2003": "nonbreaking",
+		"nospace":                 "nospace",
+	}
+
+	for text, expected := range testcases {
+		result := nospace(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestCatCatsp(t *testing.T) {
+	testcases := []struct {
+		args  []interface{}
+		cat   string
+		catsp string
+	}{
+		{nil, "", ""},
+		{[]interface{}{"a"}, "a", "a"},
+		{[]interface{}{"v", 1, 2.5, true}, "v12.5true", "v 1 2.5 true"},
+		{[]interface{}{"a", "", "b"}, "ab", "a  b"},
+	}
+
+	for _, testcase := range testcases {
+		result := cat(testcase.args...)
+		if result != testcase.cat {
+			t.Errorf("cat %v: expected %q, got %q", testcase.args, testcase.cat, result)
+		}
+
+		result = catsp(testcase.args...)
+		if result != testcase.catsp {
+			t.Errorf(
+				"catsp %v: expected %q, got %q", testcase.args, testcase.catsp, result,
+			)
+		}
+	}
+}
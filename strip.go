@@ -0,0 +1,212 @@
+package tplutil
+
+import (
+	"strings"
+)
+
+// StripMode defines how whitespace is removed from template text.
+type StripMode int
+
+const (
+	// StripAggressive removes leading whitespace of the text and every
+	// newline together with all whitespace following it, so output
+	// whitespace must be specified explicitly, like `{{"\n"}}`.
+	StripAggressive StripMode = iota
+
+	// StripConservative works like StripAggressive, but keeps blank lines
+	// between content: every blank line in source becomes single newline
+	// in output. Blank lines at the beginning and at the end of text are
+	// removed.
+	StripConservative
+
+	// StripNone keeps text as is, which is useful for whitespace-sensitive
+	// files loaded along with stripped ones.
+	StripNone
+
+	// StripKeepLines removes only indentation, spaces and tabs at the
+	// beginning of every line, and keeps all newlines as written, see
+	// StripSmart.
+	StripKeepLines
+)
+
+// Strip removes insignificant whitespace from text: leading whitespace of
+// the text and every newline together with all whitespace following it.
+// Whitespace inside lines and at their ends is kept.
+//
+// It is the same as StripWith(text, StripAggressive).
+func Strip(text string) string {
+	return StripWith(text, StripAggressive)
+}
+
+// StripSmart removes indentation from every line of text, but keeps line
+// breaks as written, so every line of template source, including blank
+// one, produces a line of output and `{{"\n"}}` is not needed:
+//
+//	{{range .}}
+//	    {{.Name}}: {{.Value}}
+//	{{end}}
+//
+// renders every item on its own line, but also leaves empty lines in
+// place of `{{range}}` and `{{end}}`. Unlike StripAggressive, lines can't
+// be joined by splitting template across several lines, so actions which
+// print nothing should be put on lines with content or trimmed with
+// `{{-` and `-}}`. Indentation of output must be specified explicitly,
+// like `{{"  "}}`, same as with Strip. Whitespace at ends of lines, which
+// includes `\r` of Windows line endings, is kept.
+//
+// It is the same as StripWith(text, StripKeepLines).
+func StripSmart(text string) string {
+	return StripWith(text, StripKeepLines)
+}
+
+// StripBytesTo appends stripped form of src to dst and returns extended
+// slice, like append does. Result is byte for byte the same as of Strip,
+// but dst can be reused between calls to avoid allocating new result
+// every time:
+//
+//	buf = tplutil.StripBytesTo(buf[:0], text)
+func StripBytesTo(dst, src []byte) []byte {
+	return appendStripped(dst, src, StripAggressive)
+}
+
+// StripWith removes whitespace from text according to mode.
+//
+// Stripping is implemented as a single pass over text, so it works in
+// linear time, no matter how long whitespace runs are.
+//
+// Only whitespace bytes are ever removed: with all whitespace dropped,
+// input and output are byte for byte identical in every mode. Text is
+// processed as bytes, so invalid UTF-8 is passed through untouched.
+func StripWith(text string, mode StripMode) string {
+	switch mode {
+	case StripNone:
+		return text
+	case StripKeepLines:
+		return stripIndent(text)
+	}
+
+	return string(appendStripped(make([]byte, 0, len(text)), text, mode))
+}
+
+// appendStripped appends text stripped with StripAggressive or
+// StripConservative mode to dst. It is shared by string and byte slice
+// functions, so they can't produce different results.
+func appendStripped[T string | []byte](dst []byte, text T, mode StripMode) []byte {
+	for i := 0; i < len(text); i++ {
+		if i == 0 || text[i] == '\n' {
+			start := i
+			newlines := 0
+			for i < len(text) && isInsignificantSpace(text[i]) {
+				if text[i] == '\n' {
+					newlines++
+				}
+				i++
+			}
+			if i == len(text) {
+				break
+			}
+			if mode == StripConservative && start > 0 {
+				for ; newlines > 1; newlines-- {
+					dst = append(dst, '\n')
+				}
+			}
+		}
+		dst = append(dst, text[i])
+	}
+
+	return dst
+}
+
+// StripWithFrontMatter does the same as StripWith, but passes leading
+// front matter block through verbatim. Front matter block starts with
+// `---` (YAML) or `+++` (TOML) line at the very beginning of text and
+// ends with the next line of the same delimiter. If there is no such
+// block, whole text is stripped.
+func StripWithFrontMatter(text string, mode StripMode) string {
+	front, body := splitFrontMatter(text)
+
+	return front + StripWith(body, mode)
+}
+
+// splitFrontMatter returns front matter block of text, including
+// delimiters and newline after closing one, and the rest of text.
+func splitFrontMatter(text string) (string, string) {
+	delim := strings.TrimRight(text[:lineEnd(text, 0)], "\r")
+	if delim != "---" && delim != "+++" {
+		return "", text
+	}
+
+	for start := lineEnd(text, 0) + 1; start < len(text); {
+		end := lineEnd(text, start)
+		if strings.TrimRight(text[start:end], "\r") == delim {
+			if end < len(text) {
+				end++
+			}
+			return text[:end], text[end:]
+		}
+		start = end + 1
+	}
+
+	return "", text
+}
+
+// lineEnd returns index of newline ending line which starts at start, or
+// length of text for the last line.
+func lineEnd(text string, start int) int {
+	end := strings.IndexByte(text[start:], '\n')
+	if end == -1 {
+		return len(text)
+	}
+
+	return start + end
+}
+
+// stripIndent removes spaces and tabs at the beginning of every line of
+// text.
+func stripIndent(text string) string {
+	buf := &strings.Builder{}
+	buf.Grow(len(text))
+
+	lineStart := true
+	for i := 0; i < len(text); i++ {
+		if lineStart && (text[i] == ' ' || text[i] == '\t') {
+			continue
+		}
+		lineStart = text[i] == '\n'
+		buf.WriteByte(text[i])
+	}
+
+	return buf.String()
+}
+
+// StripLineComments removes whole lines of text which start with prefix
+// after indentation, like `# note for template authors`. It is meant to
+// be applied to template source before stripping, since comment lines are
+// recognized by newlines. Prefix appearing in the middle of line is left
+// untouched.
+func StripLineComments(text, prefix string) string {
+	if prefix == "" {
+		return text
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimLeft(line, " \t"), prefix) {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, "")
+}
+
+// isInsignificantSpace reports whether c is one of whitespace characters
+// removed by Strip, which are the same as matched by `\s` in regexp.
+func isInsignificantSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
+	default:
+		return false
+	}
+}
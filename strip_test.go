@@ -0,0 +1,293 @@
+package tplutil
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStripWithFrontMatter(t *testing.T) {
+	testcases := []struct {
+		text     string
+		expected string
+	}{
+		{
+			"---\ntitle: x\n  nested: y\n---\n  body\n  {{.}}\n",
+			"---\ntitle: x\n  nested: y\n---\nbody{{.}}",
+		},
+		{
+			"+++\ntitle = 'x'\n+++\n  body\n",
+			"+++\ntitle = 'x'\n+++\nbody",
+		},
+		{
+			"---\r\ntitle: x\r\n---\r\n  body",
+			"---\r\ntitle: x\r\n---\r\nbody",
+		},
+		{"---\nunclosed\n  body", "---unclosedbody"},
+		{"+++\nmismatched\n---\n  body", "+++mismatched---body"},
+		{"  ---\nnot at start\n---\n", "---not at start---"},
+		{"---", "---"},
+		{"", ""},
+	}
+
+	for _, testcase := range testcases {
+		result := StripWithFrontMatter(testcase.text, StripAggressive)
+		if result != testcase.expected {
+			t.Errorf(
+				"%q: expected %q, got %q",
+				testcase.text, testcase.expected, result,
+			)
+		}
+	}
+}
+
+// randomTemplates returns count pseudo-random short texts built of
+// whitespace and a few other bytes, which covers corner cases of stripping
+// better than realistic templates.
+func randomTemplates(count int) []string {
+	alphabet := []byte(" \t\n\r\f\va{}\"")
+	random := rand.New(rand.NewSource(1))
+
+	texts := make([]string, count)
+	for i := range texts {
+		text := make([]byte, random.Intn(16))
+		for j := range text {
+			text[j] = alphabet[random.Intn(len(alphabet))]
+		}
+		texts[i] = string(text)
+	}
+
+	return texts
+}
+
+func TestStripBytesTo_MatchesStrip(t *testing.T) {
+	buf := []byte("prefix:")
+
+	for _, text := range randomTemplates(100000) {
+		buf = StripBytesTo(buf[:len("prefix:")], []byte(text))
+
+		if string(buf[len("prefix:"):]) != Strip(text) {
+			t.Fatalf(
+				"%q: StripBytesTo returned %q, Strip returned %q",
+				text, buf[len("prefix:"):], Strip(text),
+			)
+		}
+
+		if string(buf[:len("prefix:")]) != "prefix:" {
+			t.Fatalf("%q: dst contents are overwritten: %q", text, buf)
+		}
+	}
+}
+
+var smallTemplate = []byte(strings.Repeat(
+	"\t\t{{range .}}\n\t\t\t# {{.}}{{\"\\n\"}}\n\t\t{{end}}\n", 5,
+))
+
+func BenchmarkStripBytesTo_Reuse(b *testing.B) {
+	b.ReportAllocs()
+
+	var buf []byte
+	for i := 0; i < b.N; i++ {
+		buf = StripBytesTo(buf[:0], smallTemplate)
+	}
+}
+
+func BenchmarkStripBytesTo_Fresh(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = StripBytesTo(nil, smallTemplate)
+	}
+}
+
+// reInsignificantWhitespace is the regular expression Strip used to be
+// implemented with, it is kept as reference implementation.
+var reInsignificantWhitespace = regexp.MustCompile(`(?m)\n?^\s*`)
+
+func TestStrip_MatchesRegexp(t *testing.T) {
+	for _, text := range randomTemplates(100000) {
+		expected := reInsignificantWhitespace.ReplaceAllString(text, "")
+		if result := Strip(text); result != expected {
+			t.Fatalf("%q: expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func FuzzStrip(f *testing.F) {
+	f.Add("")
+	f.Add("  a\n  b\r\n\t\v c  \n\n")
+	f.Add("\t{{range .}}\n\t\t{{.}}{{\"\\n\"}}\n\t{{end}}\n")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		stripped := Strip(text)
+
+		if again := Strip(stripped); again != stripped {
+			t.Errorf("%q: not idempotent: %q, then %q", text, stripped, again)
+		}
+
+		expected := reInsignificantWhitespace.ReplaceAllString(text, "")
+		if stripped != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, stripped)
+		}
+	})
+}
+
+var largeTemplate = strings.Repeat(
+	"\t\t{{range .}}\n\t\t\t# {{.}}{{\"\\n\"}}\n\t\t{{end}}\n", 10000,
+) + strings.Repeat(" \n", 100000)
+
+func BenchmarkStrip(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = Strip(largeTemplate)
+	}
+}
+
+func BenchmarkStrip_Regexp(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = reInsignificantWhitespace.ReplaceAllString(largeTemplate, "")
+	}
+}
+
+func TestStrip_IgnoresIndentationStyle(t *testing.T) {
+	spaces := "\n    Some list:{{\"\\n\"}}\n\n    {{range .}}\n        # {{.}}{{\"\\n\"}}\n    {{end}}\n"
+	tabs := "\n\tSome list:{{\"\\n\"}}\n\n\t{{range .}}\n\t\t# {{.}}{{\"\\n\"}}\n\t{{end}}\n"
+	mixed := "\n  \tSome list:{{\"\\n\"}}\n\t \n\t {{range .}}\n\t    # {{.}}{{\"\\n\"}}\n \t{{end}}\n"
+
+	expected := "Some list:{{\"\\n\"}}{{range .}}# {{.}}{{\"\\n\"}}{{end}}"
+
+	for name, text := range map[string]string{
+		"spaces": spaces,
+		"tabs":   tabs,
+		"mixed":  mixed,
+	} {
+		if result := Strip(text); result != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, result)
+		}
+	}
+}
+
+func TestStrip_PreservesExplicitTab(t *testing.T) {
+	tpl := MustParseString("tab", `
+		{{range .}}
+			{{"\t"}}{{.}}{{"\n"}}
+		{{end}}
+	`)
+
+	result, err := ExecuteToString(tpl, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "\ta\n\tb\n" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestStripWith_Modes(t *testing.T) {
+	text := "\n\n\theader\n\n\n\t\tline 1\n\t\tline 2  \n\n\tfooter\n\n"
+
+	testcases := map[StripMode]string{
+		StripAggressive:   "headerline 1line 2  footer",
+		StripConservative: "header\n\nline 1line 2  \nfooter",
+		StripNone:         text,
+	}
+
+	for mode, expected := range testcases {
+		result := StripWith(text, mode)
+		if result != expected {
+			t.Errorf("mode %d: expected %q, got %q", mode, expected, result)
+		}
+	}
+
+	if Strip(text) != StripWith(text, StripAggressive) {
+		t.Error("Strip differs from StripAggressive")
+	}
+}
+
+func TestWithStripMode(t *testing.T) {
+	tpl, err := ParseString(
+		"conservative", "\ta\n\n\tb\n", WithStripMode(StripConservative),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "a\nb" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestStripLineComments(t *testing.T) {
+	testcases := []struct {
+		prefix   string
+		text     string
+		expected string
+	}{
+		{"#", "# note\nkept\n", "kept\n"},
+		{"#", "\t\t# indented note\n\tkept\n", "\tkept\n"},
+		{"#", "value # trailing\n", "value # trailing\n"},
+		{"#", "a\n#last", "a\n"},
+		{"//", "// note\n# kept\n", "# kept\n"},
+		{"", "# kept\n", "# kept\n"},
+	}
+
+	for _, testcase := range testcases {
+		result := StripLineComments(testcase.text, testcase.prefix)
+		if result != testcase.expected {
+			t.Errorf(
+				"%q %q: expected %q, got %q",
+				testcase.prefix, testcase.text, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestStripSmart(t *testing.T) {
+	testcases := map[string]string{
+		"\theader\n\n\t\tline 1\n\t\tline 2  \n": "header\n\nline 1\nline 2  \n",
+		"  a\r\n  b":                             "a\r\nb",
+		"a\n \n\t\n":                             "a\n\n\n",
+		"":                                       "",
+	}
+
+	for text, expected := range testcases {
+		result := StripSmart(text)
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", text, expected, result)
+		}
+
+		if StripWith(text, StripKeepLines) != result {
+			t.Errorf("%q: StripSmart differs from StripKeepLines", text)
+		}
+	}
+}
+
+func TestStripSmart_PreservesLineBreaksOfTemplate(t *testing.T) {
+	tpl, err := ParseString("main", `
+		Items:
+		{{range .}}
+		  {{"  "}}- {{.}}
+		{{end}}
+
+		Total: {{len .}}
+	`, WithStripMode(StripKeepLines))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\nItems:\n\n  - a\n\n  - b\n\n\nTotal: 2\n"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
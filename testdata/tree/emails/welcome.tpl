@@ -0,0 +1,2 @@
+{{template "layouts/header"}}
+	{{.Name}}!
@@ -0,0 +1,3 @@
+{{define "layouts/header"}}
+	Hello,{{" "}}
+{{end}}
@@ -0,0 +1,90 @@
+package tplutil
+
+import (
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+)
+
+var clock = struct {
+	sync.RWMutex
+	now func() time.Time
+}{now: time.Now}
+
+// Time provides functions to work with time values:
+//
+//	{{now}}                          // current time
+//	{{humanizeDuration .Elapsed}}    // 3 minutes
+//	{{relTime .CreatedAt}}           // 3 minutes ago, in 2 hours
+//
+// Current time is taken from the clock set by SetNow.
+var Time = template.FuncMap{
+	"now":              now,
+	"humanizeDuration": humanizeDuration,
+	"relTime":          relTime,
+}
+
+// SetNow overrides function used to get current time by Time functions,
+// which is useful in tests. Passing nil restores time.Now.
+func SetNow(fn func() time.Time) {
+	if fn == nil {
+		fn = time.Now
+	}
+
+	clock.Lock()
+	defer clock.Unlock()
+
+	clock.now = fn
+}
+
+func now() time.Time {
+	clock.RLock()
+	defer clock.RUnlock()
+
+	return clock.now()
+}
+
+var durationUnits = []struct {
+	size     time.Duration
+	singular string
+	plural   string
+}{
+	{24 * time.Hour, "day", "days"},
+	{time.Hour, "hour", "hours"},
+	{time.Minute, "minute", "minutes"},
+	{time.Second, "second", "seconds"},
+}
+
+// humanizeDuration formats duration using the largest unit that fits in
+// it, truncating the rest: 90 minutes becomes "1 hour". Sign is ignored.
+func humanizeDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	for _, unit := range durationUnits {
+		if d >= unit.size {
+			count := int(d / unit.size)
+			return fmt.Sprintf(
+				"%d %s", count, pluralize(count, unit.singular, unit.plural),
+			)
+		}
+	}
+
+	return "0 seconds"
+}
+
+// relTime describes t relative to the current time, like "3 minutes ago"
+// or "in 2 hours". Times within a second from now are reported as "now".
+func relTime(t time.Time) string {
+	d := t.Sub(now())
+	switch {
+	case d > -time.Second && d < time.Second:
+		return "now"
+	case d < 0:
+		return humanizeDuration(d) + " ago"
+	default:
+		return "in " + humanizeDuration(d)
+	}
+}
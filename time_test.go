@@ -0,0 +1,61 @@
+package tplutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	testcases := map[time.Duration]string{
+		0:                              "0 seconds",
+		500 * time.Millisecond:         "0 seconds",
+		time.Second:                    "1 second",
+		45 * time.Second:               "45 seconds",
+		time.Minute:                    "1 minute",
+		3*time.Minute + 20*time.Second: "3 minutes",
+		90 * time.Minute:               "1 hour",
+		-2 * time.Hour:                 "2 hours",
+		24 * time.Hour:                 "1 day",
+		10*24*time.Hour + 23*time.Hour: "10 days",
+	}
+
+	for duration, expected := range testcases {
+		result := humanizeDuration(duration)
+		if result != expected {
+			t.Errorf("%s: expected %q, got %q", duration, expected, result)
+		}
+	}
+}
+
+func TestRelTime(t *testing.T) {
+	current := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	SetNow(func() time.Time { return current })
+	defer SetNow(nil)
+
+	testcases := map[time.Duration]string{
+		0:                       "now",
+		-500 * time.Millisecond: "now",
+		-30 * time.Second:       "30 seconds ago",
+		-3 * time.Minute:        "3 minutes ago",
+		2 * time.Hour:           "in 2 hours",
+		time.Minute:             "in 1 minute",
+		-3 * 24 * time.Hour:     "3 days ago",
+	}
+
+	for offset, expected := range testcases {
+		result := relTime(current.Add(offset))
+		if result != expected {
+			t.Errorf("%s: expected %q, got %q", offset, expected, result)
+		}
+	}
+}
+
+func TestSetNow_NilRestoresTimeNow(t *testing.T) {
+	SetNow(func() time.Time { return time.Time{} })
+	SetNow(nil)
+
+	if now().IsZero() {
+		t.Error("clock is not restored")
+	}
+}
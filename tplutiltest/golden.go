@@ -0,0 +1,107 @@
+// Package tplutiltest provides helpers for testing template output against
+// golden files.
+//
+//	func TestReport(t *testing.T) {
+//		tpl := tplutil.MustParseString("report", reportText)
+//		tplutiltest.RenderGolden(t, tpl, data, "testdata/report.golden")
+//	}
+//
+// Run tests with `-update` flag to rewrite golden files with current
+// output:
+//
+//	go test ./... -update
+//
+// Package registers `-update` flag on import, so test binary should not
+// define flag with the same name.
+package tplutiltest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+var update = flag.Bool("update", false, "update golden files of tplutiltest")
+
+// RenderGolden executes tpl with v and compares result with contents of
+// goldenPath. On mismatch test fails with line by line diff of expected
+// and actual output. If `-update` flag is set, golden file (and missing
+// parent directories) is written with actual output instead.
+func RenderGolden(
+	t testing.TB, tpl *template.Template, v interface{}, goldenPath string,
+) {
+	t.Helper()
+
+	buffer := &bytes.Buffer{}
+	err := tpl.Execute(buffer, v)
+	if err != nil {
+		t.Fatalf("can't execute template %q: %s", tpl.Name(), err)
+	}
+
+	if *update {
+		err = os.MkdirAll(filepath.Dir(goldenPath), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(goldenPath, buffer.Bytes(), 0644)
+		}
+		if err != nil {
+			t.Fatalf("can't update golden file %q: %s", goldenPath, err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf(
+			"can't read golden file %q (run with -update to create it): %s",
+			goldenPath, err,
+		)
+	}
+
+	if !bytes.Equal(golden, buffer.Bytes()) {
+		t.Errorf(
+			"output of template %q doesn't match golden file %q:\n%s",
+			tpl.Name(), goldenPath, diffLines(string(golden), buffer.String()),
+		)
+	}
+}
+
+// diffLines returns lines which differ between want and got, prefixed
+// with line number and `-` for expected or `+` for actual line. Lines are
+// quoted, so differences in whitespace are visible.
+func diffLines(want, got string) string {
+	wantLines := strings.SplitAfter(want, "\n")
+	gotLines := strings.SplitAfter(got, "\n")
+
+	length := len(wantLines)
+	if len(gotLines) > length {
+		length = len(gotLines)
+	}
+
+	diff := &strings.Builder{}
+	for i := 0; i < length; i++ {
+		var wantLine, gotLine string
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine == gotLine {
+			continue
+		}
+		if i < len(wantLines) {
+			fmt.Fprintf(diff, "%4d - %q\n", i+1, wantLine)
+		}
+		if i < len(gotLines) {
+			fmt.Fprintf(diff, "%4d + %q\n", i+1, gotLine)
+		}
+	}
+
+	return diff.String()
+}
@@ -0,0 +1,158 @@
+package tplutiltest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// recorder is testing.TB which records failures instead of reporting
+// them, so failing calls of RenderGolden can be checked.
+type recorder struct {
+	testing.TB
+	failures []string
+	fatal    bool
+}
+
+type fatalError struct{}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	panic(fatalError{})
+}
+
+// renderGolden calls RenderGolden with recorder and returns it.
+func renderGolden(
+	tpl *template.Template, v interface{}, goldenPath string,
+) (r *recorder) {
+	r = &recorder{}
+
+	defer func() {
+		if err := recover(); err != nil {
+			if _, ok := err.(fatalError); !ok {
+				panic(err)
+			}
+		}
+	}()
+
+	RenderGolden(r, tpl, v, goldenPath)
+
+	return r
+}
+
+// setUpdate sets value of -update flag until end of test.
+func setUpdate(t *testing.T, value bool) {
+	previous := *update
+	*update = value
+	t.Cleanup(func() { *update = previous })
+}
+
+func TestRenderGolden_Matches(t *testing.T) {
+	setUpdate(t, false)
+
+	goldenPath := filepath.Join(t.TempDir(), "hello.golden")
+	err := ioutil.WriteFile(goldenPath, []byte("Hello, John!\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := template.Must(template.New("hello").Parse("Hello, {{.}}!\n"))
+
+	r := renderGolden(tpl, "John", goldenPath)
+	if len(r.failures) != 0 {
+		t.Errorf("unexpected failures: %q", r.failures)
+	}
+}
+
+func TestRenderGolden_ReportsMismatch(t *testing.T) {
+	setUpdate(t, false)
+
+	goldenPath := filepath.Join(t.TempDir(), "hello.golden")
+	err := ioutil.WriteFile(goldenPath, []byte("Hello,\nJohn!\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := template.Must(template.New("hello").Parse("Hello,\nJane!\n"))
+
+	r := renderGolden(tpl, nil, goldenPath)
+	if len(r.failures) != 1 || r.fatal {
+		t.Fatalf("expected single non-fatal failure, got %q", r.failures)
+	}
+
+	if !strings.Contains(r.failures[0], `2 - "John!\n"`) ||
+		!strings.Contains(r.failures[0], `2 + "Jane!\n"`) {
+		t.Errorf("failure doesn't contain diff: %s", r.failures[0])
+	}
+
+	if strings.Contains(r.failures[0], `"Hello,\n"`) {
+		t.Errorf("failure contains equal lines: %s", r.failures[0])
+	}
+}
+
+func TestRenderGolden_ReportsMissingGoldenFile(t *testing.T) {
+	setUpdate(t, false)
+
+	goldenPath := filepath.Join(t.TempDir(), "missing.golden")
+	tpl := template.Must(template.New("hello").Parse("Hello"))
+
+	r := renderGolden(tpl, nil, goldenPath)
+	if !r.fatal || !strings.Contains(r.failures[0], "-update") {
+		t.Errorf("expected fatal failure suggesting -update, got %q", r.failures)
+	}
+}
+
+func TestRenderGolden_ReportsExecutionError(t *testing.T) {
+	setUpdate(t, true)
+
+	goldenPath := filepath.Join(t.TempDir(), "hello.golden")
+	tpl := template.Must(template.New("hello").Parse("{{.Missing}}"))
+
+	r := renderGolden(tpl, struct{}{}, goldenPath)
+	if !r.fatal {
+		t.Errorf("expected fatal failure, got %q", r.failures)
+	}
+
+	_, err := ioutil.ReadFile(goldenPath)
+	if err == nil {
+		t.Error("golden file is written on execution error")
+	}
+}
+
+func TestRenderGolden_Update(t *testing.T) {
+	setUpdate(t, true)
+
+	goldenPath := filepath.Join(t.TempDir(), "nested", "hello.golden")
+	tpl := template.Must(template.New("hello").Parse("Hello, {{.}}!\n"))
+
+	r := renderGolden(tpl, "John", goldenPath)
+	if len(r.failures) != 0 {
+		t.Fatalf("unexpected failures: %q", r.failures)
+	}
+
+	golden, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(golden) != "Hello, John!\n" {
+		t.Errorf("unexpected golden file contents: %q", golden)
+	}
+
+	setUpdate(t, false)
+
+	r = renderGolden(tpl, "John", goldenPath)
+	if len(r.failures) != 0 {
+		t.Errorf("updated golden file doesn't match: %q", r.failures)
+	}
+}
@@ -0,0 +1,36 @@
+package tplutil
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"text/template"
+)
+
+// URL provides functions to build links:
+//
+//	{{urlPathEscape "a b/c"}}                      // a%20b%2Fc
+//	{{urlQueryEscape "a b&c"}}                     // a+b%26c
+//	{{buildQuery (dict "q" "go templates" "p" 2)}} // p=2&q=go+templates
+var URL = template.FuncMap{
+	"urlPathEscape":  url.PathEscape,
+	"urlQueryEscape": url.QueryEscape,
+	"buildQuery":     buildQuery,
+}
+
+// buildQuery returns URL query string with keys and values of map m,
+// which should have string keys. Values are formatted like toString does.
+// Keys are sorted, so output is stable.
+func buildQuery(m interface{}) (string, error) {
+	value := reflect.ValueOf(m)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return "", fmt.Errorf("buildQuery: expected map with string keys, got %T", m)
+	}
+
+	query := url.Values{}
+	for _, key := range value.MapKeys() {
+		query.Set(key.String(), toString(value.MapIndex(key).Interface()))
+	}
+
+	return query.Encode(), nil
+}
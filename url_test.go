@@ -0,0 +1,73 @@
+package tplutil
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestURLEscape(t *testing.T) {
+	tpl, err := ParseText(template.New("main").Funcs(URL), `
+		{{urlPathEscape "a b/c?d"}}|{{urlQueryEscape "a b&c=d/é"}}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a%20b%2Fc%3Fd|a+b%26c%3Dd%2F%C3%A9"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	testcases := []struct {
+		m        interface{}
+		expected string
+	}{
+		{map[string]string{}, ""},
+		{
+			map[string]string{"q": "go templates", "a": "1&2", "m": "x=y"},
+			"a=1%262&m=x%3Dy&q=go+templates",
+		},
+		{
+			map[string]interface{}{"page": 2, "debug": true},
+			"debug=true&page=2",
+		},
+	}
+
+	for _, testcase := range testcases {
+		for i := 0; i < 10; i++ {
+			result, err := buildQuery(testcase.m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != testcase.expected {
+				t.Fatalf(
+					"%v: expected %q, got %q", testcase.m, testcase.expected, result,
+				)
+			}
+		}
+	}
+}
+
+func TestBuildQuery_ReturnsErrorOnInvalidArgument(t *testing.T) {
+	testcases := []interface{}{
+		nil,
+		"q=1",
+		[]string{"q"},
+		map[int]string{1: "a"},
+	}
+
+	for _, m := range testcases {
+		_, err := buildQuery(m)
+		if err == nil {
+			t.Errorf("%#v: expected error", m)
+		}
+	}
+}
@@ -23,10 +23,11 @@
 //
 // Output will be exactly the same.
 //
-// Any indenting whitespaces and newlines will be ignored. If must, they
+// Any indenting whitespaces and newlines will be ignored, whether
+// indentation is made of tabs, spaces or any mix of them. If must, they
 // should be specified by using syntax
 //
-//	`{{" "}}` or `{{"\n"}}`.
+//	`{{" "}}`, `{{"\t"}}` or `{{"\n"}}`.
 //
 // It also provide `{{last}}` function to check on last element of pipeline:
 //
@@ -42,28 +43,283 @@
 //			{{end}}
 //		`))
 //
+// Functions and FuncMaps of the package are safe for concurrent use.
+// Package-level settings, like SetNow or SetColorMode, are guarded
+// internally, but are meant to be configured once at program start:
+// SetDefaultFuncs, for instance, affects only templates created after the
+// call. Types with methods, like SetBuilder, are not safe for concurrent
+// use. Parsed templates follow text/template rules: they can be executed
+// concurrently, but should not be parsed into meanwhile.
 package tplutil
 
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
+	"sync"
 	"text/template"
 )
 
-var reInsignificantWhitespace = regexp.MustCompile(`(?m)\n?^\s*`)
+var reUndefinedFunc = regexp.MustCompile(`function "([^"]+)" not defined`)
 
+// Last provides `{{last $i $}}` function, which reports whether $i is the
+// index of last element of $. It is false for values without length,
+// like nil or numbers, instead of failing the whole render.
 var Last = template.FuncMap{
 	"last": func(x int, a interface{}) bool {
-		return x == reflect.ValueOf(a).Len()-1
+		length, ok := lengthOf(a)
+		return ok && x == length-1
 	},
 }
 
-func Strip(text string) string {
-	return reInsignificantWhitespace.ReplaceAllString(text, ``)
+// First provides `{{first $i $}}` function, which reports whether $i is
+// the index of first element of non-empty $.
+var First = template.FuncMap{
+	"first": func(x int, a interface{}) bool {
+		length, ok := lengthOf(a)
+		return ok && length > 0 && x == 0
+	},
+}
+
+// lengthOf returns length of a if it is array, slice, map, string or
+// channel.
+func lengthOf(a interface{}) (int, bool) {
+	value := reflect.ValueOf(a)
+	switch value.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String, reflect.Chan:
+		return value.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// ParseOption configures how parse functions of the package handle
+// template text.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	leftDelim     string
+	rightDelim    string
+	disallowEmpty bool
+	stripMode     StripMode
+	trimPrefix    string
+	commentPrefix string
+	frontMatter   bool
+	checkDelims   bool
+	include       bool
+	excludes      []string
+
+	// ownTemplate is set when template is created by the package, so its
+	// delimiters are known to be `{{` and `}}` unless set with WithDelims.
+	ownTemplate bool
+}
+
+// WithDelims sets action delimiters to the specified strings, like
+// template.Delims does. Useful when template text clashes with `{{ }}`.
+func WithDelims(left, right string) ParseOption {
+	return func(config *parseConfig) {
+		config.leftDelim = left
+		config.rightDelim = right
+	}
+}
+
+// WithStripMode sets how whitespace is stripped from template text,
+// StripAggressive is used by default.
+func WithStripMode(mode StripMode) ParseOption {
+	return func(config *parseConfig) {
+		config.stripMode = mode
+	}
+}
+
+// WithTrimPrefix removes prefix from names of templates loaded from files,
+// which is useful with ParseGlobRel and ParseFSTree to get names like
+// `emails/welcome.tpl` instead of `templates/emails/welcome.tpl`
+// regardless of where root of loaded files is.
+func WithTrimPrefix(prefix string) ParseOption {
+	return func(config *parseConfig) {
+		config.trimPrefix = prefix
+	}
+}
+
+// WithLineComments removes lines starting with prefix from template text
+// before stripping, see StripLineComments.
+func WithLineComments(prefix string) ParseOption {
+	return func(config *parseConfig) {
+		config.commentPrefix = prefix
+	}
+}
+
+// WithFrontMatter passes leading front matter block of template text
+// through verbatim, see StripWithFrontMatter. Line comments set with
+// WithLineComments are removed only after the block as well.
+func WithFrontMatter() ParseOption {
+	return func(config *parseConfig) {
+		config.frontMatter = true
+	}
+}
+
+// DisallowEmpty makes parse functions return error for templates which
+// are empty after stripping, which usually means that file was blanked by
+// mistake. Intentionally empty partials are allowed by default.
+func DisallowEmpty() ParseOption {
+	return func(config *parseConfig) {
+		config.disallowEmpty = true
+	}
+}
+
+// CheckDelims makes parse errors of templates with unbalanced action
+// delimiters more descriptive: if parsing fails and numbers of left and
+// right delimiters of stripped text differ, error says so, which is
+// easier to understand than parse error about text collapsed by Strip.
+//
+// Delimiters are counted as plain substrings, so the check runs only after
+// parsing has failed and never rejects valid template. Delimiters set
+// with WithDelims are counted, `{{` and `}}` for templates created by
+// the package otherwise. Delimiters of template passed by caller can't be
+// known, so without WithDelims parse error is returned as is for it.
+func CheckDelims() ParseOption {
+	return func(config *parseConfig) {
+		config.checkDelims = true
+	}
+}
+
+// WithInclude adds functions returned by Include to the template set
+// before parsing, bound to that set. Pass it to ReplaceTemplate as well,
+// so `include` and `macro` of the returned clone render templates of the
+// clone rather than of base.
+func WithInclude() ParseOption {
+	return func(config *parseConfig) {
+		config.include = true
+	}
+}
+
+// Exclude makes functions which load files by patterns skip files
+// matching any of globs, like partials or editor backups:
+//
+//	tplutil.ParseGlob(tpl, "templates/*", tplutil.Exclude("_*", "*~"))
+//
+// Globs are matched against both base name and full path of every file,
+// using filepath.Match for files on disk and path.Match for io/fs.
+func Exclude(globs ...string) ParseOption {
+	return func(config *parseConfig) {
+		config.excludes = append(config.excludes, globs...)
+	}
+}
+
+func newParseConfig(opts []ParseOption) *parseConfig {
+	config := &parseConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// delims returns configured action delimiters, defaulting to `{{` and
+// `}}`. It reports false if delimiters are unknown, since template is
+// passed by caller and delimiters are not set with WithDelims.
+func (config *parseConfig) delims() (string, string, bool) {
+	if !config.ownTemplate &&
+		config.leftDelim == "" && config.rightDelim == "" {
+		return "", "", false
+	}
+
+	left, right := config.leftDelim, config.rightDelim
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+
+	return left, right, true
+}
+
+// apply sets configured delimiters on tpl, if any, and binds Include
+// functions to it if requested.
+func (config *parseConfig) apply(tpl *template.Template) {
+	if config.leftDelim != "" || config.rightDelim != "" {
+		tpl.Delims(config.leftDelim, config.rightDelim)
+	}
+	if config.include {
+		tpl.Funcs(Include(tpl))
+	}
+}
+
+// ParseString creates new template with given name and parses stripped
+// text into it.
+func ParseString(name, text string, opts ...ParseOption) (
+	*template.Template, error,
+) {
+	config := newParseConfig(opts)
+	config.ownTemplate = true
+
+	return parseText(newTemplate(name), text, config)
+}
+
+// MustParseString is like ParseString, but panics on error.
+func MustParseString(name, text string, opts ...ParseOption) *template.Template {
+	return template.Must(ParseString(name, text, opts...))
+}
+
+// ParseText do the same as tpl.Parse(), but strips text before parsing.
+func ParseText(tpl *template.Template, text string, opts ...ParseOption) (
+	*template.Template, error,
+) {
+	return parseText(tpl, text, newParseConfig(opts))
+}
+
+func parseText(tpl *template.Template, text string, config *parseConfig) (
+	*template.Template, error,
+) {
+	config.apply(tpl)
+
+	err := parseStripped(tpl, tpl.Name(), text, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return tpl, nil
+}
+
+// ReplaceTemplate returns a clone of base, where template named name is
+// replaced with (or added as) stripped newText. base itself is never
+// modified, so it can keep serving while new text is being parsed, and
+// swapped with returned set only if parsing succeeds, which is useful for
+// hot reload.
+//
+// Delimiters of base are inherited by the clone, but options which control
+// stripping and checks, like WithStripMode, WithLineComments,
+// WithFrontMatter, DisallowEmpty and CheckDelims, are not stored in base,
+// so they should be passed again to be applied to newText.
+//
+// Functions of base are copied to the clone as is, so functions returned
+// by Include(base) keep rendering templates of base, including old
+// version of the replaced one. Parse base with WithInclude instead of
+// adding Include manually and pass WithInclude here too, so functions are
+// bound to the clone.
+func ReplaceTemplate(
+	base *template.Template, name, newText string, opts ...ParseOption,
+) (*template.Template, error) {
+	clone, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	config := newParseConfig(opts)
+	config.apply(clone)
+
+	err = parseStripped(clone, name, newText, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return clone, nil
 }
 
 // ExecuteToString applies a parsed template to specified data object and
@@ -76,38 +332,500 @@ func ExecuteToString(tpl *template.Template, v interface{}) (string, error) {
 	return buf.String(), err
 }
 
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+// ExecuteEach applies a parsed template to every element of items, which
+// should be a slice or an array, and returns outputs in the same order.
+// Single buffer is reused for all elements. Execution stops at the first
+// error, which includes index of the failed element.
+func ExecuteEach(tpl *template.Template, items interface{}) ([]string, error) {
+	value, err := listValue(items)
+	if err != nil {
+		return nil, fmt.Errorf("template: %s", err)
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer bufferPool.Put(buf)
+
+	results := make([]string, value.Len())
+	for i := range results {
+		buf.Reset()
+
+		err := tpl.Execute(buf, value.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("template: item %d: %w", i, err)
+		}
+
+		results[i] = buf.String()
+	}
+
+	return results, nil
+}
+
+// ExecuteToStringStrict do the same as ExecuteToString(), but never
+// returns partial result: on error result is always empty, so
+// half-rendered output can't be used by accident.
+func ExecuteToStringStrict(tpl *template.Template, v interface{}) (
+	string, error,
+) {
+	result, err := ExecuteToString(tpl, v)
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+// JoinDocuments joins docs into single multi-document stream, putting
+// sep on its own line between every two documents, like `---` between
+// YAML documents of Kubernetes manifests. There is no separator before the
+// first and after the last document; documents themselves are kept as is.
+func JoinDocuments(sep string, docs ...string) string {
+	buf := &strings.Builder{}
+	for i, doc := range docs {
+		if i > 0 {
+			if !strings.HasSuffix(docs[i-1], "\n") {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(sep)
+			buf.WriteString("\n")
+		}
+		buf.WriteString(doc)
+	}
+
+	return buf.String()
+}
+
+// ExecuteToBuilder applies a parsed template to specified data object and
+// appends output to sb, so output of many templates can be accumulated in
+// single buffer. On error sb keeps everything written before it.
+func ExecuteToBuilder(
+	sb *strings.Builder, tpl *template.Template, v interface{},
+) error {
+	return tpl.Execute(sb, v)
+}
+
+// Renderer returns io.WriterTo, which executes tpl with v directly into
+// writer passed to WriteTo, without buffering whole output in memory.
+// Template is executed on every WriteTo call.
+func Renderer(tpl *template.Template, v interface{}) io.WriterTo {
+	return renderer{tpl: tpl, data: v}
+}
+
+type renderer struct {
+	tpl  *template.Template
+	data interface{}
+}
+
+// WriteTo executes template into w. It returns number of bytes written
+// to w, which includes partial output in case of execution error.
+func (r renderer) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{writer: w}
+	err := r.tpl.Execute(counter, r.data)
+
+	return counter.count, err
+}
+
+// countingWriter counts bytes successfully written to underlying writer.
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	written, err := w.writer.Write(data)
+	w.count += int64(written)
+
+	return written, err
+}
+
+// WithFuncs returns a clone of tpl with funcs added to it, leaving tpl
+// untouched, so it can be used to render shared template with
+// request-specific functions, like localized translation func.
+//
+// Since functions are resolved at parse time, tpl should be parsed with
+// placeholder functions of the same names, which will be overridden in
+// the clone.
+func WithFuncs(tpl *template.Template, funcs template.FuncMap) (
+	*template.Template, error,
+) {
+	clone, err := tpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return clone.Funcs(funcs), nil
+}
+
+// ExecuteToStringFuncs do the same as ExecuteToString(), but executes
+// clone of tpl with funcs added, like WithFuncs() does, so funcs are
+// available only for this call and tpl is left untouched.
+func ExecuteToStringFuncs(
+	tpl *template.Template, v interface{}, funcs template.FuncMap,
+) (string, error) {
+	clone, err := WithFuncs(tpl, funcs)
+	if err != nil {
+		return "", err
+	}
+
+	return ExecuteToString(clone, v)
+}
+
+// ExecuteTemplateToString do the same as ExecuteToString(), but applies
+// template of the set tpl with the given name, like tpl.ExecuteTemplate
+// does.
+func ExecuteTemplateToString(
+	tpl *template.Template, name string, v interface{},
+) (string, error) {
+	buf := &bytes.Buffer{}
+	err := tpl.ExecuteTemplate(buf, name, v)
+
+	return buf.String(), err
+}
+
+// ExecuteTemplateOrDefault do the same as ExecuteTemplateToString(), but
+// falls back to template named fallback if tpl has no template named
+// name, which allows override hierarchies like themes. It is an error if
+// neither template exists.
+func ExecuteTemplateOrDefault(
+	tpl *template.Template, name, fallback string, v interface{},
+) (string, error) {
+	if tpl.Lookup(name) == nil {
+		if tpl.Lookup(fallback) == nil {
+			return "", fmt.Errorf(
+				"template: neither %q nor %q is associated with template %q",
+				name, fallback, tpl.Name(),
+			)
+		}
+		name = fallback
+	}
+
+	return ExecuteTemplateToString(tpl, name, v)
+}
+
+// ExecuteToStringTrimmed do the same as ExecuteToString(), but trims
+// leading and trailing whitespace of the result, including partial one.
+func ExecuteToStringTrimmed(tpl *template.Template, v interface{}) (
+	string, error,
+) {
+	result, err := ExecuteToString(tpl, v)
+
+	return strings.TrimSpace(result), err
+}
+
+// ExecuteToStringOpts do the same as ExecuteToString(), but applies
+// options (like "missingkey=error", see template.Option) for this call
+// only. Options are set on a clone of tpl, so tpl itself stays unchanged
+// and can be safely used concurrently.
+func ExecuteToStringOpts(
+	tpl *template.Template, v interface{}, opts ...string,
+) (result string, err error) {
+	if len(opts) == 0 {
+		return ExecuteToString(tpl, v)
+	}
+
+	clone, err := tpl.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("template: %v", recovered)
+		}
+	}()
+
+	clone.Option(opts...)
+
+	return ExecuteToString(clone, v)
+}
+
 // ParseGlob do the same as template.ParseGlob(), but will allow to
 // use sparse syntax (like in examples above) in files.
-func ParseGlob(tpl *template.Template, pattern string) (
+func ParseGlob(tpl *template.Template, pattern string, opts ...ParseOption) (
 	*template.Template, error,
 ) {
+	return parseGlob(tpl, pattern, func(filename string) (string, error) {
+		return filepath.Base(filename), nil
+	}, opts)
+}
+
+// MustParseGlob is like ParseGlob, but panics on error. Panic message
+// includes pattern and the error.
+func MustParseGlob(
+	tpl *template.Template, pattern string, opts ...ParseOption,
+) *template.Template {
+	tpl, err := ParseGlob(tpl, pattern, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("tplutil: can't parse glob %#q: %s", pattern, err))
+	}
+
+	return tpl
+}
+
+// ParseFiles do the same as template.ParseFiles(), but will allow to use
+// sparse syntax in files, like ParseGlob() does.
+func ParseFiles(tpl *template.Template, filenames ...string) (
+	*template.Template, error,
+) {
+	return ParseFilesOpts(tpl, filenames)
+}
+
+// ParseFilesOpts do the same as ParseFiles(), but applies given options,
+// like WithStripMode or DisallowEmpty, to every parsed file. File list is
+// passed as slice, since ParseFiles already takes variadic filenames.
+func ParseFilesOpts(
+	tpl *template.Template, filenames []string, opts ...ParseOption,
+) (*template.Template, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("template: no files named in call to ParseFiles")
+	}
+
+	return parseFiles(tpl, filenames, ioutil.ReadFile, func(filename string) (string, error) {
+		return filepath.Base(filename), nil
+	}, opts)
+}
+
+// MustParseFiles is like ParseFiles, but panics on error. Panic message
+// includes filenames and the error.
+func MustParseFiles(
+	tpl *template.Template, filenames ...string,
+) *template.Template {
+	return MustParseFilesOpts(tpl, filenames)
+}
+
+// MustParseFilesOpts is like ParseFilesOpts, but panics on error. Panic
+// message includes filenames and the error.
+func MustParseFilesOpts(
+	tpl *template.Template, filenames []string, opts ...ParseOption,
+) *template.Template {
+	tpl, err := ParseFilesOpts(tpl, filenames, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("tplutil: can't parse files %q: %s", filenames, err))
+	}
+
+	return tpl
+}
+
+// ParseGlobRel do the same as ParseGlob(), but names templates after
+// paths of files relative to root, using forward slashes as separator,
+// so `templates/emails/welcome.tpl` loaded with root `templates` can be
+// referenced as `{{template "emails/welcome.tpl"}}`.
+func ParseGlobRel(
+	tpl *template.Template, root, pattern string, opts ...ParseOption,
+) (*template.Template, error) {
+	return parseGlob(tpl, pattern, func(filename string) (string, error) {
+		name, err := filepath.Rel(root, filename)
+		if err != nil {
+			return "", err
+		}
+		return filepath.ToSlash(name), nil
+	}, opts)
+}
+
+func parseGlob(
+	tpl *template.Template,
+	pattern string,
+	nameOf func(filename string) (string, error),
+	opts []ParseOption,
+) (*template.Template, error) {
+	sources, err := readGlob(pattern, newParseConfig(opts).excludes)
+	if err != nil {
+		return nil, err
+	}
+	return parseSources(tpl, sources, nameOf, opts)
+}
+
+// readGlob reads files matching pattern, skipping ones matching any of
+// excludes. Names of returned sources are left empty.
+func readGlob(pattern string, excludes []string) ([]source, error) {
 	filenames, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, err
 	}
 	if len(filenames) == 0 {
+		if !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("template: file not found: %#q", pattern)
+		}
 		return nil, fmt.Errorf("template: pattern matches no files: %#q", pattern)
 	}
+	filenames, err = excludeFiles(filenames, excludes, filepath.Match, filepath.Base)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("template: all files matching %#q are excluded", pattern)
+	}
+	return readFiles(filenames, ioutil.ReadFile)
+}
+
+// excludeFiles returns filenames which match none of excludes by base
+// name or full path.
+func excludeFiles(
+	filenames []string,
+	excludes []string,
+	match func(pattern, name string) (bool, error),
+	base func(filename string) string,
+) ([]string, error) {
+	if len(excludes) == 0 {
+		return filenames, nil
+	}
+
+	kept := []string{}
 	for _, filename := range filenames {
-		b, err := ioutil.ReadFile(filename)
+		excluded := false
+		for _, exclude := range excludes {
+			byBase, err := match(exclude, base(filename))
+			if err != nil {
+				return nil, fmt.Errorf("template: bad exclude pattern %#q: %s", exclude, err)
+			}
+			byPath, _ := match(exclude, filename)
+			if byBase || byPath {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, filename)
+		}
+	}
+
+	return kept, nil
+}
+
+// source is text of a template to be parsed. filename is empty for
+// templates which are not read from files.
+type source struct {
+	name     string
+	filename string
+	text     string
+}
+
+// readFiles reads every of filenames using readFile. Names of returned
+// sources are left empty.
+func readFiles(
+	filenames []string, readFile func(filename string) ([]byte, error),
+) ([]source, error) {
+	sources := make([]source, len(filenames))
+	for i, filename := range filenames {
+		b, err := readFile(filename)
 		if err != nil {
 			return nil, err
 		}
-		s := Strip(string(b))
-		name := filepath.Base(filename)
+		sources[i] = source{filename: filename, text: string(b)}
+	}
+	return sources, nil
+}
+
+// parseFiles reads every of filenames using readFile and parses them into
+// tpl under names returned by nameOf. If tpl is nil, it is created and
+// named after the first file.
+func parseFiles(
+	tpl *template.Template,
+	filenames []string,
+	readFile func(filename string) ([]byte, error),
+	nameOf func(filename string) (string, error),
+	opts []ParseOption,
+) (*template.Template, error) {
+	sources, err := readFiles(filenames, readFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseSources(tpl, sources, nameOf, opts)
+}
+
+// parseSources parses sources read from files into tpl under names
+// returned by nameOf for their filenames. If tpl is nil, it is created
+// and named after the first file.
+func parseSources(
+	tpl *template.Template,
+	sources []source,
+	nameOf func(filename string) (string, error),
+	opts []ParseOption,
+) (*template.Template, error) {
+	config := newParseConfig(opts)
+
+	for i, src := range sources {
+		name, err := nameOf(src.filename)
+		if err != nil {
+			return nil, err
+		}
+		name = strings.TrimPrefix(name, config.trimPrefix)
 		if tpl == nil {
-			tpl = template.New(name)
+			tpl = newTemplate(name)
+			config.ownTemplate = true
 		}
-		var current_tpl *template.Template
-		if name == tpl.Name() {
-			current_tpl = tpl
-		} else {
-			current_tpl = tpl.New(name)
+		if i == 0 {
+			config.apply(tpl)
 		}
-		_, err = current_tpl.Parse(s)
+		err = parseStripped(tpl, name, src.text, config)
 		if err != nil {
-			return nil, err
+			return nil, parseError(src.filename, err)
 		}
 	}
 	return tpl, nil
 }
+
+// parseStripped strips text and parses it into template of the set tpl
+// named name, replacing previous definition if it exists.
+func parseStripped(
+	tpl *template.Template, name, text string, config *parseConfig,
+) error {
+	front, body := "", text
+	if config.frontMatter {
+		front, body = splitFrontMatter(text)
+	}
+
+	stripped := front + StripWith(
+		StripLineComments(body, config.commentPrefix), config.stripMode,
+	)
+
+	if config.disallowEmpty && stripped == "" {
+		return fmt.Errorf("template: %s is empty after stripping", name)
+	}
+
+	var current_tpl *template.Template
+	if name == tpl.Name() {
+		current_tpl = tpl
+	} else {
+		current_tpl = tpl.New(name)
+	}
+	_, err := current_tpl.Parse(stripped)
+	if err != nil && config.checkDelims {
+		return checkDelims(name, stripped, config, err)
+	}
+
+	return err
+}
+
+// parseError annotates err returned while parsing file with path to the
+// file and, if err is caused by undefined function, with its name.
+func parseError(filename string, err error) error {
+	match := reUndefinedFunc.FindStringSubmatch(err.Error())
+	if match != nil {
+		return fmt.Errorf("%s: undefined function %q: %w", filename, match[1], err)
+	}
+
+	return fmt.Errorf("%s: %w", filename, err)
+}
+
+// checkDelims annotates parse error err of text with numbers of action
+// delimiters if they are obviously unbalanced, which otherwise leads to
+// cryptic parse errors about stripped text. err is returned as is
+// otherwise.
+func checkDelims(name, text string, config *parseConfig, err error) error {
+	left, right, ok := config.delims()
+	if ok && strings.Count(text, left) != strings.Count(text, right) {
+		return fmt.Errorf(
+			"template: unbalanced template delimiters in %s: %d %#q and %d %#q: %w",
+			name, strings.Count(text, left), left, strings.Count(text, right), right,
+			err,
+		)
+	}
+
+	return err
+}
@@ -0,0 +1,1153 @@
+package tplutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// writeFiles creates files with given contents under dir, creating
+// parent directories as needed.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckDelims_ReportsUnbalancedDelimiters(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"broken.tpl": "{{ .Foo\n\tbar\n",
+	})
+
+	_, err := ParseGlob(nil, filepath.Join(dir, "*.tpl"), CheckDelims())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if !strings.Contains(err.Error(), "unbalanced template delimiters in broken.tpl") {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(err.Error(), filepath.Join(dir, "broken.tpl")) {
+		t.Errorf("error doesn't mention file: %s", err)
+	}
+}
+
+func TestCheckDelims_AcceptsLiteralDelimiters(t *testing.T) {
+	testcases := []string{
+		`{"a": {"b": {{.B}}}}`,
+		`{{"}}"}}`,
+		`{{"{{"}}`,
+	}
+
+	for _, text := range testcases {
+		for _, opts := range [][]ParseOption{nil, {CheckDelims()}} {
+			_, err := ParseString("test", text, opts...)
+			if err != nil {
+				t.Errorf("%q: unexpected error: %s", text, err)
+			}
+		}
+	}
+}
+
+func TestParseGlob_RespectsTemplateDelims(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl": "{{ literal <% .A %>",
+	})
+
+	tpl, err := ParseGlob(
+		template.New("a.tpl").Delims("<%", "%>"), filepath.Join(dir, "*.tpl"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, map[string]int{"A": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "{{ literal 1" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestCheckDelims_RespectsTemplateDelims(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl": "{{ literal <% .A\n",
+	})
+
+	_, err := ParseGlob(
+		template.New("a.tpl").Delims("<%", "%>"), filepath.Join(dir, "*.tpl"),
+		CheckDelims(),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if strings.Contains(err.Error(), "unbalanced") {
+		t.Errorf("unknown delimiters are counted: %s", err)
+	}
+
+	_, err = ParseGlob(
+		nil, filepath.Join(dir, "*.tpl"), WithDelims("<%", "%>"), CheckDelims(),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expected := "unbalanced template delimiters in a.tpl: 1 `<%` and 0 `%>`"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+// TestConcurrentUse is meant to be run with -race flag.
+func TestConcurrentUse(t *testing.T) {
+	tpl := template.New("shared").Funcs(Default).Funcs(Rand)
+	_, err := ParseText(tpl, `
+		{{range $i, $_ := .}}
+			{{if matches "^[a-z]+$" .}}{{.}}{{end}}
+			{{if not (last $i $)}},{{end}}
+		{{end}}
+		{{randItem .}}{{now | printf "%T"}}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []string{"a", "b", "C"}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				text := Strip("\t{{.}}\n\t\t-\n  x")
+				if text != "{{.}}-x" {
+					t.Errorf("unexpected stripped text: %q", text)
+					return
+				}
+
+				_, err := ExecuteToString(tpl, data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				own, err := ParseText(template.New("own").Funcs(Regexp), `
+					{{matches "^x" .}}
+				`)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				_, err = ExecuteToString(own, "x")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestReplaceTemplate_ParseFailureLeavesBaseUnchanged(t *testing.T) {
+	base := MustParseString("main", `[{{template "x" .}}]`)
+	_, err := base.New("x").Parse("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReplaceTemplate(base, "x", "{{.Broken")
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+
+	result, err := ExecuteToString(base, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "[old]" {
+		t.Errorf("base is modified: %q", result)
+	}
+}
+
+func TestReplaceTemplate_ReplacesTemplate(t *testing.T) {
+	base := MustParseString("main", `[{{template "x" .}}]`)
+	_, err := base.New("x").Parse("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := ReplaceTemplate(base, "x", "\n\tnew\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tpl, expected := range map[*template.Template]string{
+		base:  "[old]",
+		clone: "[new]",
+	} {
+		result, err := ExecuteToString(tpl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+}
+
+func TestReplaceTemplate_RebindsInclude(t *testing.T) {
+	base, err := ParseString(
+		"main", `[{{include "x" .}}]`, WithInclude(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ParseText(base.New("x"), "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := ReplaceTemplate(base, "x", "new", WithInclude())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tpl, expected := range map[*template.Template]string{
+		base:  "[old]",
+		clone: "[new]",
+	} {
+		result, err := ExecuteToString(tpl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+}
+
+func TestWithFrontMatter(t *testing.T) {
+	tpl, err := ParseString("page", `---
+title: {{.Title}}
+# YAML comment
+tags:
+  - a
+---
+	# dev note
+	<h1>
+		{{.Title}}
+	</h1>
+`, WithFrontMatter(), WithLineComments("#"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, map[string]string{"Title": "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "---\ntitle: Hi\n# YAML comment\ntags:\n  - a\n---\n<h1>Hi</h1>"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestExclude_SkipsMatchingFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl":     "a",
+		"b.tpl":     "<% . %>",
+		"_part.tpl": "part",
+		"a.tpl~":    "backup {{",
+	})
+
+	tpl, err := ParseGlob(
+		nil, filepath.Join(dir, "*"),
+		Exclude("_*"), Exclude("*~"),
+		WithDelims("<%", "%>"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"_part.tpl", "a.tpl~"} {
+		if tpl.Lookup(name) != nil {
+			t.Errorf("excluded %s is registered", name)
+		}
+	}
+
+	result, err := ExecuteTemplateToString(tpl, "b.tpl", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "x" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestExclude_MatchesFullPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl": "a",
+		"b.tpl": "b",
+	})
+
+	tpl, err := ParseGlob(
+		nil, filepath.Join(dir, "*.tpl"), Exclude(filepath.Join(dir, "a.*")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Lookup("a.tpl") != nil || tpl.Lookup("b.tpl") == nil {
+		t.Errorf("unexpected templates: %s", tpl.DefinedTemplates())
+	}
+}
+
+func TestExclude_ReportsExclusionOfAllFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl": "a",
+	})
+
+	for _, pattern := range []string{"*.tpl", "a.tpl"} {
+		_, err := ParseGlob(nil, filepath.Join(dir, pattern), Exclude("*"))
+		if err == nil || !strings.Contains(err.Error(), "are excluded") {
+			t.Errorf("%s: unexpected error: %v", pattern, err)
+		}
+	}
+
+	_, err := ParseGlob(nil, filepath.Join(dir, "*"), Exclude("["))
+	if err == nil || !strings.Contains(err.Error(), "bad exclude pattern") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// removeSpaces removes ASCII whitespace bytes from text. It works with
+// bytes rather than runes, so stray bytes of invalid UTF-8 joined by
+// stripping don't look like different text.
+func removeSpaces(text string) string {
+	result := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		if !strings.ContainsRune(" \t\n\v\f\r", rune(text[i])) {
+			result = append(result, text[i])
+		}
+	}
+
+	return string(result)
+}
+
+func FuzzStrip_RemovesOnlyWhitespace(f *testing.F) {
+	f.Add("  a\n  b\r\n\t\v c  \n\n")
+	f.Add("---\n  front: matter\n---\n\t{{.}}\n")
+	f.Add("\xe6\x8c\n\x84")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		results := map[string]string{
+			"Strip":        Strip(text),
+			"StripBytesTo": string(StripBytesTo(nil, []byte(text))),
+			"StripSmart":   StripSmart(text),
+			"conservative": StripWith(text, StripConservative),
+			"keep lines":   StripWith(text, StripKeepLines),
+			"none":         StripWith(text, StripNone),
+			"front matter": StripWithFrontMatter(text, StripAggressive),
+			"front matter, conservative": StripWithFrontMatter(
+				text, StripConservative,
+			),
+		}
+
+		expected := removeSpaces(text)
+		for name, result := range results {
+			if removeSpaces(result) != expected {
+				t.Errorf("%s: %q: non-whitespace is changed: %q", name, text, result)
+			}
+		}
+	})
+}
+
+func TestWithDelims(t *testing.T) {
+	text := `
+		<ul>
+			<% range . %>
+				<li>{{ <% . %> }}</li>
+			<% end %>
+		</ul>
+	`
+
+	expected := "<ul><li>{{ a }}</li><li>{{ b }}</li></ul>"
+
+	for name, tpl := range map[string]*template.Template{
+		"ParseString": template.Must(
+			ParseString("delims", text, WithDelims("<%", "%>")),
+		),
+		"MustParseString": MustParseString("delims", text, WithDelims("<%", "%>")),
+		"ParseText": template.Must(
+			ParseText(template.New("delims"), text, WithDelims("<%", "%>")),
+		),
+	} {
+		result, err := ExecuteToString(tpl, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		if result != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, result)
+		}
+	}
+}
+
+func TestParseGlobRel(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"emails/welcome.tpl": "\tWelcome, {{.}}!\n",
+		"pages/welcome.tpl":  "\t<h1>{{template \"emails/welcome.tpl\" .}}</h1>\n",
+	})
+
+	tpl, err := ParseGlobRel(nil, dir, filepath.Join(dir, "*", "*.tpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteTemplateToString(tpl, "pages/welcome.tpl", "John")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "<h1>Welcome, John!</h1>" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestParseGlob_NamesFileAndUndefinedFunction(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"ok.tpl":     "{{.}}",
+		"broken.tpl": "\t{{frobnicate .}}\n",
+	})
+
+	_, err := ParseGlob(nil, filepath.Join(dir, "*.tpl"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	for _, part := range []string{
+		filepath.Join(dir, "broken.tpl"), `undefined function "frobnicate"`,
+	} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error doesn't contain %q: %s", part, err)
+		}
+	}
+}
+
+func TestExecuteToStringOpts_LeavesTemplateOptionsUnchanged(t *testing.T) {
+	tpl := MustParseString("opts", "[{{.missing}}]")
+
+	_, err := ExecuteToStringOpts(tpl, map[string]int{}, "missingkey=error")
+	if err == nil {
+		t.Fatal("expected error for missing key")
+	}
+
+	result, err := ExecuteToString(tpl, map[string]int{})
+	if err != nil {
+		t.Fatalf("option leaked to original template: %s", err)
+	}
+
+	if result != "[<no value>]" {
+		t.Errorf("unexpected result: %q", result)
+	}
+
+	result, err = ExecuteToStringOpts(tpl, map[string]int{"missing": 1})
+	if err != nil || result != "[1]" {
+		t.Errorf("unexpected result without options: %q, %v", result, err)
+	}
+}
+
+func TestExecuteToStringOpts_ReturnsErrorOnInvalidOption(t *testing.T) {
+	tpl := MustParseString("opts", "x")
+
+	_, err := ExecuteToStringOpts(tpl, nil, "missingkey=sometimes")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestWithFuncs(t *testing.T) {
+	tpl := template.Must(template.New("greeting").Funcs(template.FuncMap{
+		"t": func(s string) string { return s },
+	}).Parse(`{{t "hello"}}`))
+
+	clone, err := WithFuncs(tpl, template.FuncMap{
+		"t": func(s string) string { return "привет" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tpl, expected := range map[*template.Template]string{
+		tpl:   "hello",
+		clone: "привет",
+	} {
+		result, err := ExecuteToString(tpl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+}
+
+func TestDisallowEmpty(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"ok.tpl":    "content",
+		"blank.tpl": " \n\t\n  ",
+	})
+
+	_, err := ParseGlob(nil, filepath.Join(dir, "*.tpl"), DisallowEmpty())
+	if err == nil || !strings.Contains(err.Error(), "blank.tpl is empty") {
+		t.Errorf("expected error about blank.tpl, got %v", err)
+	}
+
+	tpl, err := ParseGlob(nil, filepath.Join(dir, "*.tpl"))
+	if err != nil {
+		t.Fatalf("empty templates should be allowed by default: %s", err)
+	}
+
+	result, err := ExecuteTemplateToString(tpl, "blank.tpl", nil)
+	if err != nil || result != "" {
+		t.Errorf("unexpected result: %q, %v", result, err)
+	}
+}
+
+// panicMessage returns message of panic raised by fn, or empty string if
+// fn doesn't panic.
+func panicMessage(fn func()) (message string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			message = fmt.Sprint(recovered)
+		}
+	}()
+
+	fn()
+
+	return ""
+}
+
+func TestMustParseGlob_PanicsOnNoMatch(t *testing.T) {
+	pattern := filepath.Join(t.TempDir(), "*.tpl")
+
+	message := panicMessage(func() { MustParseGlob(nil, pattern) })
+	if !strings.Contains(message, pattern) ||
+		!strings.Contains(message, "pattern matches no files") {
+		t.Errorf("unexpected panic message: %q", message)
+	}
+}
+
+func TestMustParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl": "\ta\n",
+	})
+
+	tpl := MustParseFiles(nil, filepath.Join(dir, "a.tpl"))
+	if result, _ := ExecuteToString(tpl, nil); result != "a" {
+		t.Errorf("unexpected result: %q", result)
+	}
+
+	missing := filepath.Join(dir, "missing.tpl")
+
+	message := panicMessage(func() { MustParseFiles(nil, missing) })
+	if !strings.Contains(message, missing) {
+		t.Errorf("unexpected panic message: %q", message)
+	}
+}
+
+func TestExecuteToBuilder_AppendsAcrossCalls(t *testing.T) {
+	sb := &strings.Builder{}
+
+	for _, name := range []string{"first", "second"} {
+		tpl := MustParseString(name, `{{.}};`)
+
+		err := ExecuteToBuilder(sb, tpl, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "first;second;"
+	if sb.String() != expected {
+		t.Errorf("expected %q, got %q", expected, sb.String())
+	}
+}
+
+func TestExecuteToBuilder_KeepsContentOnError(t *testing.T) {
+	sb := &strings.Builder{}
+	sb.WriteString("before;")
+
+	tpl := MustParseString("main", `partial;{{.Missing}}`)
+
+	err := ExecuteToBuilder(sb, tpl, struct{}{})
+	if err == nil {
+		t.Fatal("expected error on missing field")
+	}
+
+	expected := "before;partial;"
+	if sb.String() != expected {
+		t.Errorf("expected %q, got %q", expected, sb.String())
+	}
+}
+
+func TestParseGlob_LiteralPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"one.tpl": "one",
+	})
+
+	tpl, err := ParseGlob(nil, filepath.Join(dir, "one.tpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl.Lookup("one.tpl") == nil {
+		t.Errorf("template %q is not parsed", "one.tpl")
+	}
+
+	_, err = ParseGlob(nil, filepath.Join(dir, "missing.tpl"))
+	if err == nil || !strings.Contains(err.Error(), "file not found") {
+		t.Errorf("missing literal path: expected file not found error, got %v", err)
+	}
+
+	_, err = ParseGlob(nil, filepath.Join(dir, "*.missing"))
+	if err == nil || !strings.Contains(err.Error(), "pattern matches no files") {
+		t.Errorf("pattern: expected no match error, got %v", err)
+	}
+}
+
+func TestExecuteToStringStrict(t *testing.T) {
+	tpl := MustParseString("main", `partial;{{.Missing}}`)
+
+	result, err := ExecuteToStringStrict(tpl, struct{}{})
+	if err == nil {
+		t.Fatal("expected error on missing field")
+	}
+
+	if result != "" {
+		t.Errorf("expected empty result, got %q", result)
+	}
+
+	result, err = ExecuteToStringStrict(MustParseString("main", `{{.}}`), "ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "ok" {
+		t.Errorf("expected %q, got %q", "ok", result)
+	}
+}
+
+func TestWithTrimPrefix_TrimsNestedNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"templates/index.tpl":          "index",
+		"templates/emails/welcome.tpl": "welcome",
+		"templates/emails/en/bye.tpl":  "bye",
+	})
+
+	tpl, err := ParseGlobRel(
+		nil, dir, filepath.Join(dir, "templates", "*.tpl"),
+		WithTrimPrefix("templates/"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pattern := range []string{"*/*.tpl", "*/*/*.tpl"} {
+		_, err = ParseGlobRel(
+			tpl, dir, filepath.Join(dir, "templates", filepath.FromSlash(pattern)),
+			WithTrimPrefix("templates/"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[string]string{
+		"index.tpl":          "index",
+		"emails/welcome.tpl": "welcome",
+		"emails/en/bye.tpl":  "bye",
+	}
+
+	for name, content := range expected {
+		result, err := ExecuteTemplateToString(tpl, name, nil)
+		if err != nil {
+			t.Errorf("%q: %s", name, err)
+			continue
+		}
+
+		if result != content {
+			t.Errorf("%q: expected %q, got %q", name, content, result)
+		}
+	}
+}
+
+func TestExecuteEach(t *testing.T) {
+	tpl := MustParseString("main", `<{{.}}>`)
+
+	results, err := ExecuteEach(tpl, []int{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"<1>", "<2>", "<3>"}
+	if fmt.Sprint(results) != fmt.Sprint(expected) {
+		t.Errorf("expected %q, got %q", expected, results)
+	}
+
+	_, err = ExecuteEach(tpl, "abc")
+	if err == nil {
+		t.Error("expected error for non-list argument")
+	}
+}
+
+func TestExecuteEach_ReportsIndexOfFailedItem(t *testing.T) {
+	tpl := MustParseString("main", `{{.Name}}`)
+
+	_, err := ExecuteEach(tpl, []interface{}{
+		map[string]string{"Name": "a"},
+		map[string]string{"Name": "b"},
+		struct{}{},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "template: item 2: ") {
+		t.Errorf("expected error for item 2, got %v", err)
+	}
+}
+
+func BenchmarkExecuteEach(b *testing.B) {
+	tpl := MustParseString("main", `<li>{{.Name}}: {{.Email}}</li>`)
+	items := benchmarkItems(100)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := ExecuteEach(tpl, items)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExecuteEach_NaiveLoop(b *testing.B) {
+	tpl := MustParseString("main", `<li>{{.Name}}: {{.Email}}</li>`)
+	items := benchmarkItems(100)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		results := []string{}
+		for _, item := range items {
+			result, err := ExecuteToString(tpl, item)
+			if err != nil {
+				b.Fatal(err)
+			}
+			results = append(results, result)
+		}
+	}
+}
+
+// benchmarkItems returns count distinct items for rendering benchmarks.
+func benchmarkItems(count int) []map[string]string {
+	items := make([]map[string]string, count)
+	for i := range items {
+		items[i] = map[string]string{
+			"Name":  fmt.Sprintf("user%d", i),
+			"Email": fmt.Sprintf("user%d@example.com", i),
+		}
+	}
+
+	return items
+}
+
+func TestLastFirst(t *testing.T) {
+	last := Last["last"].(func(int, interface{}) bool)
+	first := First["first"].(func(int, interface{}) bool)
+
+	testcases := []struct {
+		index int
+		coll  interface{}
+		last  bool
+		first bool
+	}{
+		{0, nil, false, false},
+		{0, 42, false, false},
+		{0, []int{}, false, false},
+		{0, []int{1}, true, true},
+		{0, []int{1, 2}, false, true},
+		{1, []int{1, 2}, true, false},
+		{1, "ab", true, false},
+		{0, map[string]int{"a": 1}, true, true},
+	}
+
+	for _, testcase := range testcases {
+		if result := last(testcase.index, testcase.coll); result != testcase.last {
+			t.Errorf(
+				"last %d %#v: expected %t, got %t",
+				testcase.index, testcase.coll, testcase.last, result,
+			)
+		}
+
+		if result := first(testcase.index, testcase.coll); result != testcase.first {
+			t.Errorf(
+				"first %d %#v: expected %t, got %t",
+				testcase.index, testcase.coll, testcase.first, result,
+			)
+		}
+	}
+}
+
+func TestLast_DoesNotFailRender(t *testing.T) {
+	tpl, err := ParseText(
+		template.New("main").Funcs(Last).Funcs(First),
+		`{{last 0 nil}} {{last 0 42}} {{first 0 nil}} {{first 0 42}}`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "false false false false"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestExecuteToStringTrimmed(t *testing.T) {
+	tpl := MustParseString("main", `{{"\n\t"}}first  line{{"\n\n"}}second{{"\n "}}`)
+
+	result, err := ExecuteToStringTrimmed(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "first  line\n\nsecond"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestExecuteToStringTrimmed_TrimsPartialResult(t *testing.T) {
+	tpl := MustParseString("main", `{{"\n "}}partial{{"\n "}}{{.Missing}}`)
+
+	result, err := ExecuteToStringTrimmed(tpl, struct{}{})
+	if err == nil {
+		t.Fatal("expected error on missing field")
+	}
+
+	if result != "partial" {
+		t.Errorf("expected %q, got %q", "partial", result)
+	}
+}
+
+func TestExecuteTemplateToString(t *testing.T) {
+	tpl := MustParseString("main", `
+		{{define "header"}}<h1>{{.}}</h1>{{end}}
+		{{define "footer"}}<p>{{.}}</p>{{end}}
+		main
+	`)
+
+	result, err := ExecuteTemplateToString(tpl, "footer", "bye")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "<p>bye</p>" {
+		t.Errorf("expected %q, got %q", "<p>bye</p>", result)
+	}
+
+	_, err = ExecuteTemplateToString(tpl, "sidebar", nil)
+	if err == nil || !strings.Contains(err.Error(), `"sidebar"`) {
+		t.Errorf("expected error about missing template, got %v", err)
+	}
+}
+
+func TestWithLineComments(t *testing.T) {
+	text := `
+		# note for template authors
+		<p>
+			{{.}} # not a comment
+		</p>
+	`
+
+	tpl, err := ParseString("main", text, WithLineComments("#"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "<p>x # not a comment</p>" {
+		t.Errorf("unexpected result: %q", result)
+	}
+
+	tpl, err = ParseString("main", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = ExecuteToString(tpl, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(result, "# note") {
+		t.Errorf("comments are removed without option: %q", result)
+	}
+}
+
+func TestExecuteTemplateOrDefault(t *testing.T) {
+	tpl := MustParseString("main", `
+		{{define "default"}}default {{.}}{{end}}
+		{{define "dark"}}dark {{.}}{{end}}
+	`)
+
+	testcases := map[string]string{
+		"dark":  "dark x",
+		"light": "default x",
+	}
+
+	for name, expected := range testcases {
+		result, err := ExecuteTemplateOrDefault(tpl, name, "default", "x")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != expected {
+			t.Errorf("%q: expected %q, got %q", name, expected, result)
+		}
+	}
+
+	_, err := ExecuteTemplateOrDefault(tpl, "light", "fallback", "x")
+	if err == nil || !strings.Contains(err.Error(), "neither") {
+		t.Errorf("expected error about missing templates, got %v", err)
+	}
+}
+
+func TestJoinDocuments(t *testing.T) {
+	testcases := []struct {
+		docs     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"a: 1\n"}, "a: 1\n"},
+		{[]string{"a: 1\n", "b: 2\n", "c: 3\n"}, "a: 1\n---\nb: 2\n---\nc: 3\n"},
+		{[]string{"a: 1", "b: 2"}, "a: 1\n---\nb: 2"},
+	}
+
+	for _, testcase := range testcases {
+		result := JoinDocuments("---", testcase.docs...)
+		if result != testcase.expected {
+			t.Errorf(
+				"%q: expected %q, got %q",
+				testcase.docs, testcase.expected, result,
+			)
+		}
+	}
+}
+
+// limitedWriter accepts at most limit bytes and fails afterwards.
+type limitedWriter struct {
+	buf   strings.Builder
+	limit int
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.buf.Len()+len(data) > w.limit {
+		written, _ := w.buf.Write(data[:w.limit-w.buf.Len()])
+		return written, fmt.Errorf("limit of %d bytes exceeded", w.limit)
+	}
+
+	return w.buf.Write(data)
+}
+
+func TestRenderer_WriteTo(t *testing.T) {
+	renderer := Renderer(MustParseString("main", `Hello, {{.}}!`), "Юля")
+
+	for i := 0; i < 2; i++ {
+		sb := &strings.Builder{}
+
+		count, err := renderer.WriteTo(sb)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sb.String() != "Hello, Юля!" {
+			t.Errorf("unexpected output: %q", sb.String())
+		}
+
+		if count != int64(len("Hello, Юля!")) {
+			t.Errorf("expected %d bytes, got %d", len("Hello, Юля!"), count)
+		}
+	}
+}
+
+func TestRenderer_WriteTo_PropagatesErrors(t *testing.T) {
+	sb := &strings.Builder{}
+
+	renderer := Renderer(MustParseString("main", `partial;{{.Missing}}`), 42)
+
+	count, err := renderer.WriteTo(sb)
+	if err == nil {
+		t.Fatal("expected execution error")
+	}
+
+	if count != int64(len("partial;")) || sb.String() != "partial;" {
+		t.Errorf("unexpected partial output of %d bytes: %q", count, sb.String())
+	}
+
+	writer := &limitedWriter{limit: 4}
+
+	renderer = Renderer(MustParseString("main", `{{.}}`), "too long")
+
+	count, err = renderer.WriteTo(writer)
+	if err == nil || !strings.Contains(err.Error(), "limit of 4 bytes") {
+		t.Errorf("expected writer error, got %v", err)
+	}
+
+	if count != 4 {
+		t.Errorf("expected 4 bytes, got %d", count)
+	}
+}
+
+func TestExecuteToStringFuncs_LeavesTemplateUntouched(t *testing.T) {
+	tpl := template.Must(template.New("greeting").Funcs(template.FuncMap{
+		"t": func(s string) string { return s },
+	}).Parse(`{{t "hello"}}`))
+
+	result, err := ExecuteToStringFuncs(tpl, nil, template.FuncMap{
+		"t":     func(s string) string { return "привет" },
+		"extra": func() string { return "extra" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "привет" {
+		t.Errorf("expected %q, got %q", "привет", result)
+	}
+
+	result, err = ExecuteToString(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "hello" {
+		t.Errorf("original: expected %q, got %q", "hello", result)
+	}
+
+	_, err = tpl.New("other").Parse(`{{extra}}`)
+	if err == nil {
+		t.Error("one-off function is visible to original template")
+	}
+}
+
+func TestReplaceTemplate_InheritsDelims(t *testing.T) {
+	base := MustParseString("main", `<% .A %>`, WithDelims("<%", "%>"))
+
+	clone, err := ReplaceTemplate(base, "main", "\t{{literal}} <% .B %>\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(clone, map[string]int{"A": 1, "B": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "{{literal}} 2" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestParseFilesOpts(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.tpl":     "\t{{literal}}\n\n\t<% . %>\n",
+		"blank.tpl": " \n",
+	})
+
+	tpl, err := ParseFilesOpts(
+		nil, []string{filepath.Join(dir, "a.tpl")},
+		WithDelims("<%", "%>"), WithStripMode(StripConservative),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExecuteToString(tpl, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "{{literal}}\nx" {
+		t.Errorf("unexpected result: %q", result)
+	}
+
+	_, err = ParseFilesOpts(
+		nil, []string{filepath.Join(dir, "blank.tpl")}, DisallowEmpty(),
+	)
+	if err == nil || !strings.Contains(err.Error(), "blank.tpl") {
+		t.Errorf("expected error about blank.tpl, got %v", err)
+	}
+
+	message := panicMessage(func() {
+		MustParseFilesOpts(nil, []string{filepath.Join(dir, "blank.tpl")}, DisallowEmpty())
+	})
+	if !strings.Contains(message, "is empty after stripping") {
+		t.Errorf("unexpected panic message: %q", message)
+	}
+
+	_, err = ParseFilesOpts(nil, nil)
+	if err == nil {
+		t.Error("expected error on empty file list")
+	}
+}
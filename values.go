@@ -0,0 +1,239 @@
+package tplutil
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Values provides functions to inspect and choose between values:
+//
+//	{{with withDefault .Nick .Name}}Hello, {{.}}!{{end}}
+//	{{template "button" (dict "label" "OK" "kind" "primary")}}
+//	{{toInt .Count}}                       // 3 for float64(3.7) or "3"
+//	{{typeOf .Field}}                      // []string, for debugging
+//	{{if toBool .Enabled}}                 // for true, 1, "yes" or "1"
+//	{{template "config" (deepMerge .Defaults .Overrides)}}
+//
+// Value is considered empty when it is false, 0, nil pointer or
+// interface, or zero-length array, slice, map or string, same as in
+// `{{if}}` action.
+var Values = template.FuncMap{
+	"withDefault": withDefault,
+	"dict":        dict,
+	"deepMerge":   deepMerge,
+	"toInt":       toInt,
+	"toFloat":     toFloat,
+	"toString":    toString,
+	"toBool":      toBool,
+	"typeOf":      typeOf,
+	"kindOf":      kindOf,
+}
+
+func isEmpty(value interface{}) bool {
+	truth, _ := template.IsTrue(value)
+
+	return !truth
+}
+
+// withDefault returns value unless it is empty, and fallback otherwise.
+// Unlike plain `{{with}}`, block is still rendered for empty value, but
+// with fallback as dot.
+func withDefault(value, fallback interface{}) interface{} {
+	if isEmpty(value) {
+		return fallback
+	}
+
+	return value
+}
+
+// dict builds map from alternating keys and values, which is handy to
+// pass several named arguments to sub-template.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments: %d", len(pairs))
+	}
+
+	result := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is %T, not string", pairs[i], pairs[i])
+		}
+
+		result[key] = pairs[i+1]
+	}
+
+	return result, nil
+}
+
+// deepMerge returns new map with values of src merged over values of
+// dst. Nested maps present in both are merged recursively, any other
+// values of src, including slices, replace values of dst. Neither dst nor
+// src are modified, nested maps of result are copies as well.
+func deepMerge(dst, src map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(dst)+len(src))
+	for key, value := range dst {
+		result[key] = copyNested(value)
+	}
+
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]interface{})
+		dstMap, dstOK := result[key].(map[string]interface{})
+		if srcOK && dstOK {
+			result[key] = deepMerge(dstMap, srcMap)
+		} else {
+			result[key] = copyNested(value)
+		}
+	}
+
+	return result
+}
+
+// copyNested returns copy of value if it is a map with string keys, or
+// value itself otherwise.
+func copyNested(value interface{}) interface{} {
+	if nested, ok := value.(map[string]interface{}); ok {
+		return deepMerge(nested, nil)
+	}
+
+	return value
+}
+
+// toInt converts integer, float (truncating it towards zero) or numeric
+// string to int. Values which don't fit into int, including NaN and
+// infinities, are an error.
+func toInt(v interface{}) (int, error) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() < math.MinInt || value.Int() > math.MaxInt {
+			return 0, fmt.Errorf("toInt: %v is out of int range", v)
+		}
+		return int(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt {
+			return 0, fmt.Errorf("toInt: %v is out of int range", v)
+		}
+		return int(value.Uint()), nil
+	case reflect.String:
+		number, err := strconv.Atoi(strings.TrimSpace(value.String()))
+		if err == nil {
+			return number, nil
+		}
+	}
+
+	number, err := toNumber(v)
+	if err != nil {
+		return 0, fmt.Errorf("toInt: %s", err)
+	}
+
+	// -MinInt is 2^63 (or 2^31), which float64 represents exactly, unlike
+	// MaxInt. NaN fails both comparisons.
+	number = math.Trunc(number)
+	if !(number >= math.MinInt && number < -float64(math.MinInt)) {
+		return 0, fmt.Errorf("toInt: %v is out of int range", v)
+	}
+
+	return int(number), nil
+}
+
+// toFloat converts integer, float or numeric string to float64.
+func toFloat(v interface{}) (float64, error) {
+	number, err := toNumber(v)
+	if err != nil {
+		return 0, fmt.Errorf("toFloat: %s", err)
+	}
+
+	return number, nil
+}
+
+// toNumber converts any number or numeric string to float64.
+func toNumber(v interface{}) (float64, error) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value.String()), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", value.String())
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("can't convert %T to number", v)
+	}
+}
+
+// toBool converts v to bool:
+//
+//   - bool is returned as is;
+//   - number is true unless it is zero;
+//   - string is true if it is `true`, `yes`, `y` or `on` in any case, or
+//     a non-zero finite number, surrounding whitespace is ignored; any
+//     other string, including `false`, `no`, `nan`, `inf` and empty one,
+//     is false;
+//   - nil is false;
+//   - any other value is true unless it is empty, same as in `{{if}}`.
+func toBool(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool()
+	case reflect.String:
+		text := strings.ToLower(strings.TrimSpace(value.String()))
+		switch text {
+		case "true", "yes", "y", "on":
+			return true
+		}
+		number, err := strconv.ParseFloat(text, 64)
+		return err == nil && number != 0 &&
+			!math.IsNaN(number) && !math.IsInf(number, 0)
+	}
+
+	if number, err := toNumber(v); err == nil {
+		return number != 0
+	}
+
+	return !isEmpty(v)
+}
+
+// toString formats v with %v, except nil, which becomes empty string, and
+// byte slice, which is converted as is.
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// typeOf returns Go type of v, like `map[string]interface {}`, or `nil`.
+func typeOf(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+
+	return reflect.TypeOf(v).String()
+}
+
+// kindOf returns kind of v, like `map` or `struct`, or `nil`.
+func kindOf(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+
+	return reflect.TypeOf(v).Kind().String()
+}
@@ -0,0 +1,327 @@
+package tplutil
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+func TestToInt(t *testing.T) {
+	testcases := []struct {
+		value    interface{}
+		expected int
+	}{
+		{3, 3},
+		{int8(-3), -3},
+		{uint16(7), 7},
+		{3.7, 3},
+		{-3.7, -3},
+		{float32(2.5), 2},
+		{"42", 42},
+		{" 42 ", 42},
+		{"3.7", 3},
+		{"-1e3", -1000},
+		{uint64(math.MaxInt), math.MaxInt},
+		{float64(math.MinInt), math.MinInt},
+	}
+
+	for _, testcase := range testcases {
+		result, err := toInt(testcase.value)
+		if err != nil {
+			t.Errorf("%#v: unexpected error: %s", testcase.value, err)
+			continue
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"%#v: expected %d, got %d",
+				testcase.value, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestToInt_ReturnsErrorOnImpossibleConversion(t *testing.T) {
+	testcases := []interface{}{
+		"abc",
+		"",
+		nil,
+		[]int{1},
+		math.Inf(1),
+		math.Inf(-1),
+		math.NaN(),
+		1e30,
+		-1e30,
+		uint64(math.MaxUint64),
+		uint64(math.MaxInt) + 1,
+		"1e30",
+	}
+
+	for _, value := range testcases {
+		result, err := toInt(value)
+		if err == nil {
+			t.Errorf("%#v: expected error, got %d", value, result)
+		}
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	testcases := []struct {
+		value    interface{}
+		expected float64
+	}{
+		{3, 3},
+		{uint(3), 3},
+		{2.5, 2.5},
+		{"2.5", 2.5},
+		{" -1 ", -1},
+	}
+
+	for _, testcase := range testcases {
+		result, err := toFloat(testcase.value)
+		if err != nil {
+			t.Errorf("%#v: unexpected error: %s", testcase.value, err)
+			continue
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"%#v: expected %v, got %v",
+				testcase.value, testcase.expected, result,
+			)
+		}
+	}
+
+	_, err := toFloat("1,5")
+	if err == nil {
+		t.Error("expected error for non-numeric string")
+	}
+}
+
+func TestToString(t *testing.T) {
+	testcases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, ""},
+		{"s", "s"},
+		{[]byte("b"), "b"},
+		{3, "3"},
+		{2.5, "2.5"},
+		{true, "true"},
+		{[]int{1, 2}, "[1 2]"},
+	}
+
+	for _, testcase := range testcases {
+		result := toString(testcase.value)
+		if result != testcase.expected {
+			t.Errorf(
+				"%#v: expected %q, got %q",
+				testcase.value, testcase.expected, result,
+			)
+		}
+	}
+}
+
+type namedBool bool
+
+func TestToBool(t *testing.T) {
+	testcases := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{true, true},
+		{false, false},
+		{namedBool(true), true},
+		{nil, false},
+		{0, false},
+		{1, true},
+		{-2, true},
+		{0.0, false},
+		{0.5, true},
+		{uint8(0), false},
+		{"true", true},
+		{" YES ", true},
+		{"y", true},
+		{"on", true},
+		{"1", true},
+		{"2.5", true},
+		{"0", false},
+		{"0.0", false},
+		{"false", false},
+		{"no", false},
+		{"off", false},
+		{"", false},
+		{"maybe", false},
+		{"nan", false},
+		{"inf", false},
+		{"-Infinity", false},
+		{[]int{}, false},
+		{[]int{1}, true},
+		{map[string]int{}, false},
+		{struct{}{}, true},
+	}
+
+	for _, testcase := range testcases {
+		result := toBool(testcase.value)
+		if result != testcase.expected {
+			t.Errorf(
+				"%#v: expected %v, got %v",
+				testcase.value, testcase.expected, result,
+			)
+		}
+	}
+}
+
+func TestWithDefault(t *testing.T) {
+	tpl := template.Must(ParseText(template.New("greeting").Funcs(Values), `
+		{{with withDefault .Nick .Name}}Hello, {{.}}!{{end}}
+	`))
+
+	testcases := []struct {
+		data     map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"Nick": "johnny", "Name": "John"}, "Hello, johnny!"},
+		{map[string]interface{}{"Nick": "", "Name": "John"}, "Hello, John!"},
+		{map[string]interface{}{"Name": "John"}, "Hello, John!"},
+	}
+
+	for _, testcase := range testcases {
+		result, err := ExecuteToString(tpl, testcase.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != testcase.expected {
+			t.Errorf(
+				"%v: expected %q, got %q",
+				testcase.data, testcase.expected, result,
+			)
+		}
+	}
+
+	for _, empty := range []interface{}{nil, 0, false, "", []int{}, map[string]int{}} {
+		if withDefault(empty, "fallback") != "fallback" {
+			t.Errorf("%#v is not considered empty", empty)
+		}
+	}
+
+	if withDefault(0.5, "fallback") != 0.5 {
+		t.Error("non-empty value is replaced")
+	}
+}
+
+func TestTypeOfKindOf(t *testing.T) {
+	testcases := []struct {
+		value        interface{}
+		expectedType string
+		expectedKind string
+	}{
+		{namedBool(true), "tplutil.namedBool", "bool"},
+		{struct{ Name string }{}, "struct { Name string }", "struct"},
+		{map[string]interface{}{}, "map[string]interface {}", "map"},
+		{[]string{"a"}, "[]string", "slice"},
+		{&struct{}{}, "*struct {}", "ptr"},
+		{nil, "nil", "nil"},
+	}
+
+	for _, testcase := range testcases {
+		result := typeOf(testcase.value)
+		if result != testcase.expectedType {
+			t.Errorf(
+				"%#v: expected type %q, got %q",
+				testcase.value, testcase.expectedType, result,
+			)
+		}
+
+		result = kindOf(testcase.value)
+		if result != testcase.expectedKind {
+			t.Errorf(
+				"%#v: expected kind %q, got %q",
+				testcase.value, testcase.expectedKind, result,
+			)
+		}
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"name": "base",
+		"port": 80,
+		"tags": []string{"a", "b"},
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"min": 1, "max": 10},
+		},
+		"debug": map[string]interface{}{"level": 1},
+	}
+
+	src := map[string]interface{}{
+		"port": 8080,
+		"tags": []string{"c"},
+		"db": map[string]interface{}{
+			"pool": map[string]interface{}{"max": 20},
+		},
+		"debug": false,
+		"extra": true,
+	}
+
+	result := deepMerge(dst, src)
+
+	expected := map[string]interface{}{
+		"name": "base",
+		"port": 8080,
+		"tags": []string{"c"},
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"min": 1, "max": 20},
+		},
+		"debug": false,
+		"extra": true,
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDeepMerge_DoesNotModifyArguments(t *testing.T) {
+	dst := map[string]interface{}{
+		"db": map[string]interface{}{"host": "localhost"},
+	}
+	src := map[string]interface{}{
+		"db":    map[string]interface{}{"port": 5432},
+		"cache": map[string]interface{}{"size": 1},
+	}
+
+	result := deepMerge(dst, src)
+	result["db"].(map[string]interface{})["host"] = "changed"
+	result["cache"].(map[string]interface{})["size"] = 2
+
+	if !reflect.DeepEqual(dst, map[string]interface{}{
+		"db": map[string]interface{}{"host": "localhost"},
+	}) {
+		t.Errorf("dst is modified: %v", dst)
+	}
+
+	if !reflect.DeepEqual(src, map[string]interface{}{
+		"db":    map[string]interface{}{"port": 5432},
+		"cache": map[string]interface{}{"size": 1},
+	}) {
+		t.Errorf("src is modified: %v", src)
+	}
+}
+
+func TestDeepMerge_AcceptsNil(t *testing.T) {
+	result := deepMerge(nil, nil)
+	if result == nil || len(result) != 0 {
+		t.Errorf("expected empty map, got %#v", result)
+	}
+
+	result = deepMerge(nil, map[string]interface{}{"a": 1})
+	if !reflect.DeepEqual(result, map[string]interface{}{"a": 1}) {
+		t.Errorf("unexpected result: %v", result)
+	}
+}